@@ -0,0 +1,229 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package accounting
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/structure"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+// Accrual is a profit credit of a structure, Total is the profit accrued
+// by the structure up to and including this credit.
+type Accrual struct {
+	Time   uint32
+	Amount amount.Amount
+	Total  amount.Amount
+}
+
+// Ledger replays transactions and records what every address was credited
+// and debited. Structure transactions are forwarded to the registry, so the
+// configuration in effect at the time of each transfer is used.
+type Ledger struct {
+	registry *structure.Registry
+	credits  map[string]amount.Amount
+	debits   map[string]amount.Amount
+	accruals map[string][]Accrual
+}
+
+func NewLedger(r *structure.Registry) *Ledger {
+	return &Ledger{
+		registry: r,
+		credits:  make(map[string]amount.Amount),
+		debits:   make(map[string]amount.Amount),
+		accruals: make(map[string][]Accrual),
+	}
+}
+
+// ApplyBlock applies every transaction of b. The transactions are applied
+// to a copy of the ledger and the registry first, so a block with a
+// rejected transaction leaves both unchanged.
+func (l *Ledger) ApplyBlock(b *block.Block) error {
+	tmp := l.clone()
+
+	err := l.registry.Update(func(r *structure.Registry) error {
+		tmp.registry = r
+
+		for i := uint16(0); i < b.TxCount(); i++ {
+			if err := tmp.Apply(b.Transaction(i), b.Timestamp()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	l.credits, l.debits, l.accruals = tmp.credits, tmp.debits, tmp.accruals
+
+	return nil
+}
+
+// clone copies the balances of l. The accrual slices are shared, appending
+// to a copy does not change what l sees.
+func (l *Ledger) clone() *Ledger {
+	c := &Ledger{
+		registry: l.registry,
+		credits:  make(map[string]amount.Amount, len(l.credits)),
+		debits:   make(map[string]amount.Amount, len(l.debits)),
+		accruals: make(map[string][]Accrual, len(l.accruals)),
+	}
+
+	for k, v := range l.credits {
+		c.credits[k] = v
+	}
+
+	for k, v := range l.debits {
+		c.debits[k] = v
+	}
+
+	for k, v := range l.accruals {
+		c.accruals[k] = v[:len(v):len(v)]
+	}
+
+	return c
+}
+
+// Apply records t as confirmed at time ts. Genesis transactions only
+// credit the recipient. Transfers to a transit, profit or fee address of a
+// structure are not split.
+func (l *Ledger) Apply(t *transaction.Transaction, ts uint32) error {
+	switch t.Version() {
+	case transaction.Genesis:
+		return l.credit(t.Recipient(), t.Amount())
+	case transaction.Basic:
+		return l.transfer(t, ts)
+	default:
+		return l.registry.Apply(t)
+	}
+}
+
+func (l *Ledger) transfer(t *transaction.Transaction, ts uint32) error {
+	rcp, val := t.Recipient(), t.Amount()
+
+	s, ok := l.registry.Get(rcp.Prefix())
+	if !ok || s.IsTransit(rcp) || bytes.Equal(rcp.Bytes, s.ProfitAddress.Bytes) || bytes.Equal(rcp.Bytes, s.FeeAddress.Bytes) {
+		s = &structure.Structure{}
+	}
+
+	sp, err := SplitTransfer(s, val)
+	if err != nil {
+		return err
+	}
+
+	// Every sum is checked before the ledger is changed.
+	snd := t.Sender()
+
+	debit, err := l.debits[string(snd.Bytes)].Add(val)
+	if err != nil {
+		return err
+	}
+
+	credits := make(map[string]amount.Amount, 3)
+
+	for _, c := range []struct {
+		adr *address.Address
+		val amount.Amount
+	}{{rcp, sp.Recipient}, {s.ProfitAddress, sp.Profit}, {s.FeeAddress, sp.Fee}} {
+		if c.val == 0 {
+			continue
+		}
+
+		cur, ok := credits[string(c.adr.Bytes)]
+		if !ok {
+			cur = l.credits[string(c.adr.Bytes)]
+		}
+
+		if credits[string(c.adr.Bytes)], err = cur.Add(c.val); err != nil {
+			return err
+		}
+	}
+
+	var acc Accrual
+
+	if sp.Profit > 0 {
+		acc = Accrual{Time: ts, Amount: sp.Profit, Total: sp.Profit}
+
+		if prev := l.accruals[s.Prefix]; len(prev) > 0 {
+			if acc.Total, err = prev[len(prev)-1].Total.Add(sp.Profit); err != nil {
+				return err
+			}
+		}
+	}
+
+	l.debits[string(snd.Bytes)] = debit
+
+	for k, v := range credits {
+		l.credits[k] = v
+	}
+
+	if sp.Profit > 0 {
+		l.accruals[s.Prefix] = append(l.accruals[s.Prefix], acc)
+	}
+
+	return nil
+}
+
+func (l *Ledger) credit(a *address.Address, v amount.Amount) error {
+	c, err := l.credits[string(a.Bytes)].Add(v)
+	if err != nil {
+		return err
+	}
+
+	l.credits[string(a.Bytes)] = c
+
+	return nil
+}
+
+func (l *Ledger) Credited(a *address.Address) amount.Amount {
+	return l.credits[string(a.Bytes)]
+}
+
+func (l *Ledger) Debited(a *address.Address) amount.Amount {
+	return l.debits[string(a.Bytes)]
+}
+
+// Accruals returns the profit credits of the structure in replay order.
+func (l *Ledger) Accruals(prefix string) []Accrual {
+	a := make([]Accrual, len(l.accruals[prefix]))
+	copy(a, l.accruals[prefix])
+
+	return a
+}
+
+// AccruedProfit returns the profit accrued by the structure at time ts.
+func (l *Ledger) AccruedProfit(prefix string, ts uint32) amount.Amount {
+	acc := l.accruals[prefix]
+	i := sort.Search(len(acc), func(i int) bool { return acc[i].Time > ts })
+
+	if i == 0 {
+		return 0
+	}
+
+	return acc[i-1].Total
+}
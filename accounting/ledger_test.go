@@ -0,0 +1,215 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package accounting_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/umi-top/umi-core/accounting"
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/structure"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func transfer(snd, rcp *address.Address, v amount.Amount) *transaction.Transaction {
+	return transaction.NewTransaction().SetSender(snd).SetRecipient(rcp).SetAmount(v)
+}
+
+func newLedger(own *key.SecretKey) *accounting.Ledger {
+	l := accounting.NewLedger(structure.NewRegistry())
+	_ = l.Apply(transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).
+		SetSender(address.FromKey(own)).SetPrefix("aaa").SetProfitPercent(500).SetFeePercent(1000), 0)
+
+	return l
+}
+
+func TestLedger(t *testing.T) {
+	own := newKey()
+	l := newLedger(own)
+
+	srv := address.FromKey(own).SetPrefix("aaa")
+	snd := address.FromKey(newKey())
+	rcp := address.FromKey(newKey()).SetPrefix("aaa")
+	umi := address.FromKey(newKey())
+
+	_ = l.Apply(transaction.NewTransaction().SetVersion(transaction.Genesis).SetRecipient(snd).SetValue(100000), 0)
+
+	steps := []struct {
+		tx *transaction.Transaction
+		ts uint32
+	}{
+		{transfer(snd, rcp, 10000), 10},
+		{transfer(snd, umi, 10000), 20},
+		{transfer(snd, rcp, 2000), 30},
+		{transfer(snd, srv, 5000), 40},
+	}
+
+	for _, st := range steps {
+		if err := l.Apply(st.tx, st.ts); err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+	}
+
+	checks := []struct {
+		desc string
+		act  amount.Amount
+		exp  amount.Amount
+	}{
+		{"sender debit", l.Debited(snd), 27000},
+		{"sender credit", l.Credited(snd), 100000},
+		{"recipient", l.Credited(rcp), 8500 + 1700},
+		{"umi recipient", l.Credited(umi), 10000},
+		{"profit and fee address", l.Credited(srv), 500 + 1000 + 100 + 200 + 5000},
+		{"accrued at 9", l.AccruedProfit("aaa", 9), 0},
+		{"accrued at 10", l.AccruedProfit("aaa", 10), 500},
+		{"accrued at 35", l.AccruedProfit("aaa", 35), 600},
+		{"accrued at 99", l.AccruedProfit("aaa", 99), 600},
+	}
+
+	for _, c := range checks {
+		if c.act != c.exp {
+			t.Errorf("%s: expected: %d, got: %d", c.desc, c.exp, c.act)
+		}
+	}
+
+	if acc := l.Accruals("aaa"); len(acc) != 2 || acc[1] != (accounting.Accrual{Time: 30, Amount: 100, Total: 600}) {
+		t.Fatalf("Unexpected accruals: %+v", acc)
+	}
+}
+
+func TestLedgerBlock(t *testing.T) {
+	own := newKey()
+	l := newLedger(own)
+	snd, rcp := address.FromKey(newKey()), address.FromKey(newKey()).SetPrefix("aaa")
+
+	b := block.NewBlock()
+	b.SetTimestamp(1600000000)
+	b.AppendTransaction(transfer(snd, rcp, 100))
+
+	if err := l.ApplyBlock(b); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if act := l.AccruedProfit("aaa", 1600000000); act != 5 {
+		t.Fatalf("Expected: 5, got: %d", act)
+	}
+}
+
+func TestLedgerBlockRejected(t *testing.T) {
+	own := newKey()
+	reg := structure.NewRegistry()
+	l := accounting.NewLedger(reg)
+	snd, rcp := address.FromKey(newKey()), address.FromKey(newKey()).SetPrefix("aaa")
+
+	b := block.NewBlock()
+	b.SetTimestamp(1600000000)
+	b.AppendTransaction(transaction.NewTransaction().SetVersion(transaction.Genesis).SetRecipient(snd).SetValue(1000))
+	b.AppendTransaction(transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).
+		SetSender(address.FromKey(own)).SetPrefix("aaa").SetProfitPercent(500).SetFeePercent(1000))
+	b.AppendTransaction(transfer(snd, rcp, 100))
+	b.AppendTransaction(transaction.NewTransaction().SetVersion(transaction.UpdateSmartContract).
+		SetSender(address.FromKey(own)).SetPrefix("bbb").SetProfitPercent(500).SetFeePercent(1000))
+
+	if err := l.ApplyBlock(b); !errors.Is(err, structure.ErrNotFound) {
+		t.Fatalf("Expected: %v, got: %v", structure.ErrNotFound, err)
+	}
+
+	if l.Credited(snd) != 0 || l.Debited(snd) != 0 || l.Credited(rcp) != 0 || len(l.Accruals("aaa")) != 0 {
+		t.Fatalf("Expected: ledger to be unchanged")
+	}
+
+	if _, ok := reg.Get("aaa"); ok {
+		t.Fatalf("Expected: registry to be unchanged, got: %v", reg.Prefixes())
+	}
+}
+
+func TestLedgerConservation(t *testing.T) {
+	own := newKey()
+	adr := []*address.Address{
+		address.FromKey(newKey()),
+		address.FromKey(newKey()),
+		address.FromKey(newKey()).SetPrefix("aaa"),
+		address.FromKey(newKey()).SetPrefix("aaa"),
+		address.FromKey(own).SetPrefix("aaa"),
+	}
+
+	f := func(seed int64) bool {
+		l := newLedger(own)
+		rnd := rand.New(rand.NewSource(seed))
+
+		var total amount.Amount
+
+		for i := 0; i < 50; i++ {
+			v := amount.Amount(rnd.Int63n(1000000))
+			total += v
+
+			if l.Apply(transfer(adr[rnd.Intn(len(adr))], adr[rnd.Intn(len(adr))], v), uint32(i)) != nil {
+				return false
+			}
+		}
+
+		var credited, debited amount.Amount
+
+		for _, a := range adr {
+			credited += l.Credited(a)
+			debited += l.Debited(a)
+		}
+
+		return credited == total && debited == total
+	}
+
+	if err := quick.Check(f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLedgerTransit(t *testing.T) {
+	own := newKey()
+	l := newLedger(own)
+	snd, trn := address.FromKey(newKey()), address.FromKey(newKey()).SetPrefix("aaa")
+
+	_ = l.Apply(transaction.NewTransaction().SetVersion(transaction.CreateTransitAddress).
+		SetSender(address.FromKey(own)).SetRecipient(trn), 0)
+
+	if err := l.Apply(transfer(snd, trn, 10000), 1); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if act := l.Credited(trn); act != 10000 {
+		t.Fatalf("Expected: 10000, got: %d", act)
+	}
+
+	if act := l.AccruedProfit("aaa", 1); act != 0 {
+		t.Fatalf("Expected: 0, got: %d", act)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package accounting computes how Basic transfers into structure addresses
+// are distributed between the recipient, the profit address and the fee
+// address of the structure.
+//
+// Rounding rules: the profit share is ProfitPercent/10000 of the value and
+// the fee share is FeePercent/10000 of the value, each rounded down to a
+// base unit independently. Whatever is left, including the rounding
+// remainders, goes to the recipient, so the three parts always add up to
+// the transferred value.
+package accounting
+
+import (
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/structure"
+)
+
+type Split struct {
+	Recipient amount.Amount
+	Profit    amount.Amount
+	Fee       amount.Amount
+}
+
+func SplitTransfer(s *structure.Structure, v amount.Amount) (Split, error) {
+	prf, err := v.MulPercent(s.ProfitPercent)
+	if err != nil {
+		return Split{}, err
+	}
+
+	fee, err := v.MulPercent(s.FeePercent)
+	if err != nil {
+		return Split{}, err
+	}
+
+	rest, err := v.Sub(prf)
+	if err == nil {
+		rest, err = rest.Sub(fee)
+	}
+
+	if err != nil {
+		return Split{}, err
+	}
+
+	return Split{Recipient: rest, Profit: prf, Fee: fee}, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package accounting_test
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/umi-top/umi-core/accounting"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/structure"
+)
+
+func TestSplitTransfer(t *testing.T) {
+	cases := []struct {
+		val      amount.Amount
+		prf, fee uint16
+		exp      accounting.Split
+	}{
+		{10000, 100, 2000, accounting.Split{Recipient: 7900, Profit: 100, Fee: 2000}},
+		{199, 500, 2000, accounting.Split{Recipient: 151, Profit: 9, Fee: 39}},
+		{1, 500, 2000, accounting.Split{Recipient: 1}},
+		{0, 500, 2000, accounting.Split{}},
+		{amount.Max, 0, 0, accounting.Split{Recipient: amount.Max}},
+	}
+
+	for _, tc := range cases {
+		s := &structure.Structure{ProfitPercent: tc.prf, FeePercent: tc.fee}
+
+		act, err := accounting.SplitTransfer(s, tc.val)
+		if err != nil || act != tc.exp {
+			t.Errorf("For %d expected: %+v, got: %+v (%v)", tc.val, tc.exp, act, err)
+		}
+	}
+}
+
+func TestSplitConservation(t *testing.T) {
+	f := func(v uint64, prf, fee uint16) bool {
+		val := amount.Amount(v) % (amount.Max + 1)
+		s := &structure.Structure{ProfitPercent: 100 + prf%401, FeePercent: fee % 2001}
+
+		sp, err := accounting.SplitTransfer(s, val)
+		if err != nil {
+			return false
+		}
+
+		// Nothing is created or lost and each share is rounded down.
+		return sp.Recipient+sp.Profit+sp.Fee == val &&
+			uint64(sp.Profit)*10000 <= uint64(val)*uint64(s.ProfitPercent) &&
+			uint64(sp.Fee)*10000 <= uint64(val)*uint64(s.FeePercent)
+	}
+
+	if err := quick.Check(f, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Fatal(err)
+	}
+}
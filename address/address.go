@@ -22,8 +22,11 @@ package address
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 
 	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/network"
 	"github.com/umi-top/umi-core/util"
 	"github.com/umi-top/umi-core/util/bech32"
 )
@@ -45,13 +48,37 @@ func NewAddress() *Address {
 	return a
 }
 
+// NewAddressWithParams is like NewAddress but uses the default prefix of p.
+func NewAddressWithParams(p *network.Params) *Address {
+	return NewAddress().SetPrefix(p.DefaultPrefix)
+}
+
 func FromBech32(s string) *Address {
-	b, err := bech32.Decode(s)
+	a, err := ParseBech32(s)
 	if err != nil {
 		return nil
 	}
 
-	return &Address{Bytes: b}
+	return a
+}
+
+// ParseBech32 is like FromBech32 but reports why s is not an address.
+// Mistyped addresses are reported as *TypoError.
+func ParseBech32(s string) (*Address, error) {
+	b, err := bech32.Decode(s)
+	if err == nil {
+		return &Address{Bytes: b}, nil
+	}
+
+	if errors.Is(err, bech32.ErrInvalidChecksum) || errors.Is(err, bech32.ErrInvalidCharacter) {
+		if pos, fix, e := bech32.LocateErrors(s, bech32.Bech32); e == nil && len(pos) > 0 {
+			if _, e := bech32.Decode(fix); e == nil {
+				return nil, &TypoError{Positions: pos, Suggestion: fix}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
 }
 
 func FromBytes(b []byte) *Address {
@@ -69,15 +96,36 @@ func FromKey(key key.Key) *Address {
 	return a
 }
 
+// FromKeyWithParams is like FromKey but uses the default prefix of p.
+func FromKeyWithParams(key key.Key, p *network.Params) *Address {
+	return FromKey(key).SetPrefix(p.DefaultPrefix)
+}
+
 func (a *Address) Prefix() string {
 	return util.VersionToPrefix(a.Version())
 }
 
+// SetPrefix leaves a unchanged if p is not a valid prefix, use SetPrefixE
+// to detect it.
 func (a *Address) SetPrefix(p string) *Address {
-	binary.BigEndian.PutUint16(a.Bytes[0:2], util.PrefixToVersion(p))
+	_ = a.SetPrefixE(p)
+
 	return a
 }
 
+// SetPrefixE is like SetPrefix but returns util.ErrInvalidPrefix for an
+// invalid p.
+func (a *Address) SetPrefixE(p string) error {
+	v, err := util.ParsePrefix(p)
+	if err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint16(a.Bytes[0:2], v)
+
+	return nil
+}
+
 func (a *Address) PublicKey() *key.PublicKey {
 	return key.NewPublicKey(a.Bytes[2:34])
 }
@@ -96,6 +144,18 @@ func (a *Address) SetVersion(v uint16) *Address {
 	return a
 }
 
+func (a *Address) Verify() error {
+	if len(a.Bytes) != Length {
+		return ErrInvalidAddress
+	}
+
+	if _, err := util.ParseVersion(a.Version()); err != nil {
+		return ErrInvalidPrefix
+	}
+
+	return nil
+}
+
 func (a *Address) ToBech32() string {
 	return bech32.Encode(a.Bytes)
 }
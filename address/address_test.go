@@ -24,10 +24,13 @@ import (
 	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
+	"errors"
 	"testing"
 
 	"github.com/umi-top/umi-core/address"
 	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/network"
+	"github.com/umi-top/umi-core/util"
 )
 
 func TestVersion(t *testing.T) {
@@ -80,3 +83,58 @@ func TestBytes(t *testing.T) {
 		t.Fatalf("Expected: %x, got: %x", exp, act)
 	}
 }
+
+func TestInvalidPrefix(t *testing.T) {
+	for _, p := range []string{"", "ab", "ABC", "abcd"} {
+		adr := address.NewAddress().SetPrefix("aaa")
+
+		if err := adr.SetPrefixE(p); !errors.Is(err, util.ErrInvalidPrefix) {
+			t.Fatalf("Expected: %v, got: %v", util.ErrInvalidPrefix, err)
+		}
+
+		if act := adr.SetPrefix(p).Prefix(); act != "aaa" {
+			t.Fatalf("Expected: aaa, got: %s", act)
+		}
+
+		if err := adr.Verify(); err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	if err := address.NewAddress().Verify(); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := (&address.Address{}).Verify(); !errors.Is(err, address.ErrInvalidAddress) {
+		t.Fatalf("Expected: %v, got: %v", address.ErrInvalidAddress, err)
+	}
+
+	if err := address.NewAddress().SetVersion(0x8000).Verify(); !errors.Is(err, address.ErrInvalidPrefix) {
+		t.Fatalf("Expected: %v, got: %v", address.ErrInvalidPrefix, err)
+	}
+}
+
+func TestWithParams(t *testing.T) {
+	p, _ := network.NewDevnet("local").SetDefaultPrefix("dev").Build()
+	_, sec, _ := ed25519.GenerateKey(rand.Reader)
+	k := key.NewSecretKey(sec)
+
+	if act := address.NewAddressWithParams(p).Prefix(); act != "dev" {
+		t.Fatalf("Expected: dev, got: %s", act)
+	}
+
+	a := address.FromKeyWithParams(k, p)
+	if act := a.Prefix(); act != "dev" {
+		t.Fatalf("Expected: dev, got: %s", act)
+	}
+
+	if !bytes.Equal(a.PublicKey().ToBytes(), k.PublicKey().ToBytes()) {
+		t.Fatalf("Expected: key to be preserved")
+	}
+
+	if act := address.FromKeyWithParams(k, network.Mainnet()).Prefix(); act != "umi" {
+		t.Fatalf("Expected: umi, got: %s", act)
+	}
+}
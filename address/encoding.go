@@ -0,0 +1,103 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package address
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+func (a *Address) String() string {
+	if a.Verify() != nil {
+		return ""
+	}
+
+	return a.ToBech32()
+}
+
+func (a *Address) MarshalText() ([]byte, error) {
+	if err := a.Verify(); err != nil {
+		return nil, err
+	}
+
+	return []byte(a.ToBech32()), nil
+}
+
+func (a *Address) UnmarshalText(text []byte) error {
+	b, err := ParseBech32(string(text))
+	if err != nil {
+		return err
+	}
+
+	a.Bytes = b.Bytes
+
+	return nil
+}
+
+func (a *Address) MarshalBinary() ([]byte, error) {
+	if err := a.Verify(); err != nil {
+		return nil, err
+	}
+
+	return a.ToBytes(), nil
+}
+
+func (a *Address) UnmarshalBinary(data []byte) error {
+	if len(data) != Length {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidAddress, Length, len(data))
+	}
+
+	b := FromBytes(data)
+	if err := b.Verify(); err != nil {
+		return err
+	}
+
+	a.Bytes = b.Bytes
+
+	return nil
+}
+
+// Scan implements sql.Scanner. Raw 34-byte values (bytea, binary) are
+// taken as is, anything else is parsed as a bech32 string.
+func (a *Address) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		if len(v) == Length {
+			return a.UnmarshalBinary(v)
+		}
+
+		return a.UnmarshalText(v)
+	case string:
+		return a.UnmarshalText([]byte(v))
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidAddress, src)
+	}
+}
+
+// Value implements driver.Valuer, addresses are stored as bech32 strings.
+func (a *Address) Value() (driver.Value, error) {
+	b, err := a.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
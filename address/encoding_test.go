@@ -0,0 +1,130 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package address_test
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+)
+
+var (
+	_ encoding.TextMarshaler     = (*address.Address)(nil)
+	_ encoding.TextUnmarshaler   = (*address.Address)(nil)
+	_ encoding.BinaryMarshaler   = (*address.Address)(nil)
+	_ encoding.BinaryUnmarshaler = (*address.Address)(nil)
+	_ sql.Scanner                = (*address.Address)(nil)
+	_ driver.Valuer              = (*address.Address)(nil)
+	_ fmt.Stringer               = (*address.Address)(nil)
+)
+
+const bech = "umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhg"
+
+func TestText(t *testing.T) {
+	adr := &address.Address{}
+	if err := adr.UnmarshalText([]byte(bech)); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	act, _ := adr.MarshalText()
+	if bech != string(act) {
+		t.Fatalf("Expected: %s, got: %s", bech, act)
+	}
+
+	if bech != adr.String() {
+		t.Fatalf("Expected: %s, got: %s", bech, adr.String())
+	}
+}
+
+func TestBinary(t *testing.T) {
+	exp := address.FromBech32(bech).ToBytes()
+	adr := &address.Address{}
+
+	if err := adr.UnmarshalBinary(exp); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	act, _ := adr.MarshalBinary()
+	if !bytes.Equal(exp, act) {
+		t.Fatalf("Expected: %x, got: %x", exp, act)
+	}
+
+	if err := adr.UnmarshalBinary(exp[1:]); !errors.Is(err, address.ErrInvalidAddress) {
+		t.Fatalf("Expected: %v, got: %v", address.ErrInvalidAddress, err)
+	}
+
+	exp[0] = 0x80
+
+	if err := adr.UnmarshalBinary(exp); !errors.Is(err, address.ErrInvalidAddress) {
+		t.Fatalf("Expected: %v, got: %v", address.ErrInvalidAddress, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	raw := address.FromBech32(bech).ToBytes()
+
+	cases := []struct {
+		desc string
+		src  interface{}
+		err  error
+	}{
+		{"bytea", raw, nil},
+		{"text", bech, nil},
+		{"text as bytes", []byte(bech), nil},
+		{"short bytea", raw[:33], address.ErrInvalidAddress},
+		{"bad text", "umi1qqq", address.ErrInvalidAddress},
+		{"null", nil, address.ErrInvalidAddress},
+		{"int", int64(1), address.ErrInvalidAddress},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			adr := &address.Address{}
+			err := adr.Scan(tc.src)
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected: %v, got: %v", tc.err, err)
+			}
+
+			if err == nil && !bytes.Equal(raw, adr.Bytes) {
+				t.Fatalf("Expected: %x, got: %x", raw, adr.Bytes)
+			}
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	act, err := address.FromBech32(bech).Value()
+	if err != nil || act != bech {
+		t.Fatalf("Expected: %s, got: %v (%v)", bech, act, err)
+	}
+
+	if _, err := (&address.Address{}).Value(); !errors.Is(err, address.ErrInvalidAddress) {
+		t.Fatalf("Expected: %v, got: %v", address.ErrInvalidAddress, err)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package address_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	exp := `"umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhg"`
+	act, err := json.Marshal(address.FromBech32(exp[1 : len(exp)-1]))
+
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if exp != string(act) {
+		t.Fatalf("Expected: %s, got: %s", exp, act)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	exp := "umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhg"
+	act := &address.Address{}
+
+	if err := json.Unmarshal([]byte(`"`+exp+`"`), act); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if exp != act.ToBech32() {
+		t.Fatalf("Expected: %s, got: %s", exp, act.ToBech32())
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	cases := []struct {
+		desc string
+		json string
+	}{
+		{"not a string", `123`},
+		{"bad checksum", `"umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhh"`},
+		{"empty", `""`},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tc.json), &address.Address{})
+			if !errors.Is(err, address.ErrInvalidAddress) {
+				t.Fatalf("Expected: %v, got: %v", address.ErrInvalidAddress, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package address_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+)
+
+func TestParseBech32(t *testing.T) {
+	adr, err := address.ParseBech32(bech)
+	if err != nil || adr.ToBech32() != bech {
+		t.Fatalf("Expected: %s, got: %v (%v)", bech, adr, err)
+	}
+}
+
+func TestParseBech32Typo(t *testing.T) {
+	cases := []struct {
+		desc string
+		str  string
+		pos  []int
+	}{
+		{"substitution", bech[:17] + "x" + bech[18:], []int{17}},
+		{"not in alphabet", bech[:20] + "o" + bech[21:], []int{20}},
+		{"two", bech[:30] + "qq" + bech[32:], []int{30, 31}},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := address.ParseBech32(tc.str)
+
+			var typo *address.TypoError
+			if !errors.As(err, &typo) {
+				t.Fatalf("Expected: TypoError, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(tc.pos, typo.Positions) || typo.Suggestion != bech {
+				t.Fatalf("Expected: %v %s, got: %v %s", tc.pos, bech, typo.Positions, typo.Suggestion)
+			}
+
+			if !errors.Is(err, address.ErrInvalidAddress) {
+				t.Fatalf("Expected: %v, got: %v", address.ErrInvalidAddress, err)
+			}
+
+			if address.FromBech32(tc.str) != nil {
+				t.Fatal("Expected: nil")
+			}
+		})
+	}
+}
+
+func TestParseBech32Invalid(t *testing.T) {
+	for _, s := range []string{"", "umi1", "xyz" + bech[3:], bech[:4] + "zzzzz" + bech[9:]} {
+		_, err := address.ParseBech32(s)
+
+		var typo *address.TypoError
+		if errors.As(err, &typo) || !errors.Is(err, address.ErrInvalidAddress) {
+			t.Fatalf("Expected: %v, got: %v", address.ErrInvalidAddress, err)
+		}
+	}
+}
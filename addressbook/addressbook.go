@@ -0,0 +1,301 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package addressbook keeps labeled sets of addresses.
+//
+// A Book indexes addresses by prefix and then by public key, so membership
+// is two map lookups without allocation and listing one prefix does not
+// visit the others. Books are read and written as CSV ("address,label")
+// and JSON with bech32 addresses. A Book is not safe for concurrent writes.
+package addressbook
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/util"
+)
+
+var (
+	ErrInvalidAddress = errors.New("addressbook: invalid address")
+	ErrInvalidCSV     = errors.New("addressbook: invalid csv")
+	ErrInvalidJSON    = errors.New("addressbook: invalid json")
+)
+
+type pubKey [address.Length - 2]byte
+
+// Entry is an address with its label, the label may be empty.
+type Entry struct {
+	Address *address.Address `json:"address"`
+	Label   string           `json:"label,omitempty"`
+}
+
+type Book struct {
+	prefixes map[uint16]map[pubKey]string
+	n        int
+}
+
+func New() *Book {
+	return &Book{prefixes: make(map[uint16]map[pubKey]string)}
+}
+
+func split(a *address.Address) (ver uint16, pub pubKey) {
+	copy(pub[:], a.Bytes[2:address.Length])
+
+	return binary.BigEndian.Uint16(a.Bytes[0:2]), pub
+}
+
+func join(ver uint16, pub pubKey) *address.Address {
+	a := &address.Address{Bytes: make([]byte, address.Length)}
+	binary.BigEndian.PutUint16(a.Bytes[0:2], ver)
+	copy(a.Bytes[2:], pub[:])
+
+	return a
+}
+
+// Add adds a without a label, the label of a known address is kept.
+func (b *Book) Add(a *address.Address) {
+	if !b.Has(a) {
+		b.Set(a, "")
+	}
+}
+
+// Set adds a or replaces its label.
+func (b *Book) Set(a *address.Address, label string) {
+	ver, pub := split(a)
+
+	m, ok := b.prefixes[ver]
+	if !ok {
+		m = make(map[pubKey]string)
+		b.prefixes[ver] = m
+	}
+
+	if _, ok := m[pub]; !ok {
+		b.n++
+	}
+
+	m[pub] = label
+}
+
+func (b *Book) Has(a *address.Address) bool {
+	_, ok := b.Label(a)
+
+	return ok
+}
+
+// Label returns the label of a and whether a is in the book.
+func (b *Book) Label(a *address.Address) (string, bool) {
+	ver, pub := split(a)
+	label, ok := b.prefixes[ver][pub]
+
+	return label, ok
+}
+
+// Delete removes a and reports whether it was in the book.
+func (b *Book) Delete(a *address.Address) bool {
+	ver, pub := split(a)
+
+	m := b.prefixes[ver]
+	if _, ok := m[pub]; !ok {
+		return false
+	}
+
+	delete(m, pub)
+	b.n--
+
+	if len(m) == 0 {
+		delete(b.prefixes, ver)
+	}
+
+	return true
+}
+
+func (b *Book) Len() int {
+	return b.n
+}
+
+// Prefixes lists the prefixes that have addresses, sorted.
+func (b *Book) Prefixes() []string {
+	p := make([]string, 0, len(b.prefixes))
+	for ver := range b.prefixes {
+		p = append(p, util.VersionToPrefix(ver))
+	}
+
+	sort.Strings(p)
+
+	return p
+}
+
+// HasPrefix reports whether any address with prefix p is in the book.
+func (b *Book) HasPrefix(p string) bool {
+	ver, err := util.ParsePrefix(p)
+
+	return err == nil && len(b.prefixes[ver]) > 0
+}
+
+// Prefix lists the entries with prefix p, sorted by address.
+func (b *Book) Prefix(p string) []Entry {
+	ver, err := util.ParsePrefix(p)
+	if err != nil {
+		return nil
+	}
+
+	return b.appendEntries(nil, ver)
+}
+
+// Entries lists all entries sorted by address.
+func (b *Book) Entries() []Entry {
+	vers := make([]uint16, 0, len(b.prefixes))
+	for ver := range b.prefixes {
+		vers = append(vers, ver)
+	}
+
+	sort.Slice(vers, func(i, j int) bool { return vers[i] < vers[j] })
+
+	e := make([]Entry, 0, b.n)
+	for _, ver := range vers {
+		e = b.appendEntries(e, ver)
+	}
+
+	return e
+}
+
+func (b *Book) appendEntries(e []Entry, ver uint16) []Entry {
+	m := b.prefixes[ver]
+	start := len(e)
+
+	for pub, label := range m {
+		e = append(e, Entry{Address: join(ver, pub), Label: label})
+	}
+
+	s := e[start:]
+	sort.Slice(s, func(i, j int) bool { return bytes.Compare(s[i].Address.Bytes, s[j].Address.Bytes) < 0 })
+
+	return e
+}
+
+// ReadCSV adds the "address,label" records of r, a label column is
+// optional and a leading "address" header is skipped. On error b is left
+// unchanged.
+func (b *Book) ReadCSV(r io.Reader) error {
+	nb := New()
+	if err := nb.readCSV(r); err != nil {
+		return err
+	}
+
+	for ver, m := range nb.prefixes {
+		for pub, label := range m {
+			b.Set(join(ver, pub), label)
+		}
+	}
+
+	return nil
+}
+
+func (b *Book) readCSV(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+
+		if len(rec) > 2 {
+			return fmt.Errorf("%w: line %d: expected 1 or 2 fields, got %d", ErrInvalidCSV, line, len(rec))
+		}
+
+		if line == 1 && rec[0] == "address" {
+			continue
+		}
+
+		a, err := address.ParseBech32(rec[0])
+		if err != nil {
+			return fmt.Errorf("%w: line %d: %v", ErrInvalidAddress, line, err)
+		}
+
+		var label string
+		if len(rec) == 2 {
+			label = rec[1]
+		}
+
+		b.Set(a, label)
+	}
+}
+
+// WriteCSV writes a header and the entries of b sorted by address.
+func (b *Book) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"address", "label"}); err != nil {
+		return err
+	}
+
+	for _, e := range b.Entries() {
+		if err := cw.Write([]string{e.Address.ToBech32(), e.Label}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// MarshalJSON writes b as an array of entries sorted by address.
+func (b *Book) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.Entries())
+}
+
+// UnmarshalJSON replaces the contents of b.
+func (b *Book) UnmarshalJSON(data []byte) error {
+	var entries []Entry
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	nb := New()
+
+	for i, e := range entries {
+		if e.Address == nil {
+			return fmt.Errorf("%w: entry %d: missing address", ErrInvalidJSON, i)
+		}
+
+		nb.Set(e.Address, e.Label)
+	}
+
+	*b = *nb
+
+	return nil
+}
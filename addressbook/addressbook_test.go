@@ -0,0 +1,174 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package addressbook_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/addressbook"
+	"github.com/umi-top/umi-core/key"
+)
+
+func newAddress(prefix string) *address.Address {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	return address.FromKey(key.NewPublicKey(pub)).SetPrefix(prefix)
+}
+
+func TestBook(t *testing.T) {
+	b := addressbook.New()
+	exchange, cold, aaa := newAddress("umi"), newAddress("umi"), newAddress("aaa")
+
+	b.Set(exchange, "exchange")
+	b.Add(cold)
+	b.Set(cold, "cold wallet")
+	b.Add(cold)
+	b.Add(aaa)
+
+	if b.Len() != 3 {
+		t.Fatalf("Expected: %v, got: %v", 3, b.Len())
+	}
+
+	if l, ok := b.Label(cold); !ok || l != "cold wallet" {
+		t.Fatalf("Expected: %v, got: %v %v", "cold wallet", l, ok)
+	}
+
+	// same key, other prefix
+	if b.Has(address.FromBytes(exchange.Bytes).SetPrefix("aaa")) {
+		t.Fatalf("Expected: %v, got: %v", false, true)
+	}
+
+	if p := b.Prefixes(); fmt.Sprint(p) != "[aaa umi]" {
+		t.Fatalf("Expected: %v, got: %v", "[aaa umi]", p)
+	}
+
+	if e := b.Prefix("aaa"); len(e) != 1 || !bytes.Equal(e[0].Address.Bytes, aaa.Bytes) {
+		t.Fatalf("Expected: %v, got: %v", aaa.ToBech32(), e)
+	}
+
+	if e := b.Prefix("umi"); len(e) != 2 || bytes.Compare(e[0].Address.Bytes, e[1].Address.Bytes) >= 0 {
+		t.Fatalf("Expected: %v, got: %v", "2 sorted entries", e)
+	}
+
+	if !b.Delete(aaa) || b.Delete(aaa) || b.HasPrefix("aaa") || b.Len() != 2 {
+		t.Fatalf("Expected: %v, got: %v %v", "aaa deleted", b.Prefixes(), b.Len())
+	}
+}
+
+func TestCSV(t *testing.T) {
+	b := addressbook.New()
+	b.Set(newAddress("umi"), "exchange, main")
+	b.Set(newAddress("aaa"), "")
+	b.Set(newAddress("genesis"), "genesis")
+
+	var buf bytes.Buffer
+	if err := b.WriteCSV(&buf); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	c := addressbook.New()
+	if err := c.ReadCSV(&buf); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if fmt.Sprint(c.Entries()) != fmt.Sprint(b.Entries()) {
+		t.Fatalf("Expected: %v, got: %v", b.Entries(), c.Entries())
+	}
+
+	a := newAddress("umi")
+	if err := c.ReadCSV(strings.NewReader(a.ToBech32() + "\n")); err != nil || !c.Has(a) {
+		t.Fatalf("Expected: %v, got: %v", a.ToBech32(), err)
+	}
+
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{"umi1\n", addressbook.ErrInvalidAddress},
+		{a.ToBech32() + ",a,b\n", addressbook.ErrInvalidCSV},
+		{"\"" + a.ToBech32() + "\n", addressbook.ErrInvalidCSV},
+	}
+
+	for _, tt := range tests {
+		if err := addressbook.New().ReadCSV(strings.NewReader(tt.in)); !errors.Is(err, tt.err) {
+			t.Fatalf("Expected: %v, got: %v", tt.err, err)
+		}
+	}
+}
+
+func TestReadCSVAtomic(t *testing.T) {
+	b := addressbook.New()
+	a := newAddress("umi")
+	b.Set(a, "old")
+
+	in := a.ToBech32() + ",new\n" + newAddress("aaa").ToBech32() + ",aaa\numi1\n"
+	if err := b.ReadCSV(strings.NewReader(in)); !errors.Is(err, addressbook.ErrInvalidAddress) {
+		t.Fatalf("Expected: %v, got: %v", addressbook.ErrInvalidAddress, err)
+	}
+
+	if label, _ := b.Label(a); b.Len() != 1 || label != "old" {
+		t.Fatalf("Expected: [%v old], got: %v", a.ToBech32(), b.Entries())
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b := addressbook.New()
+	b.Set(newAddress("umi"), "exchange")
+	b.Set(newAddress("aaa"), "")
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	c := addressbook.New()
+	c.Add(newAddress("bbb"))
+
+	if err := json.Unmarshal(data, c); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if fmt.Sprint(c.Entries()) != fmt.Sprint(b.Entries()) {
+		t.Fatalf("Expected: %v, got: %v", b.Entries(), c.Entries())
+	}
+
+	for _, in := range []string{`{}`, `[{"label": "x"}]`, `[{"address": "umi1"}]`} {
+		if err := json.Unmarshal([]byte(in), c); !errors.Is(err, addressbook.ErrInvalidJSON) {
+			t.Fatalf("%s: Expected: %v, got: %v", in, addressbook.ErrInvalidJSON, err)
+		}
+	}
+}
+
+func TestHasAllocs(t *testing.T) {
+	b := addressbook.New()
+	a := newAddress("umi")
+	b.Set(a, "exchange")
+
+	if n := testing.AllocsPerRun(100, func() { b.Has(a) }); n != 0 {
+		t.Fatalf("Expected: %v, got: %v", 0, n)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package amount implements UMI values kept in base units, 1 UMI is
+// 100 base units.
+package amount
+
+import (
+	"encoding/json"
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+type Amount uint64
+
+const (
+	Decimals        = 2
+	Umi      Amount = 100
+	Max      Amount = 9_007_199_254_740_991
+	Zero     Amount = 0
+)
+
+var (
+	ErrInvalidAmount  = errors.New("amount: invalid amount")
+	ErrOverflow       = errors.New("amount: overflow")
+	ErrUnderflow      = errors.New("amount: underflow")
+	ErrInvalidPercent = errors.New("amount: invalid percent")
+)
+
+// Parse parses a decimal UMI string such as "12", "12.3" or "12.34".
+func Parse(s string) (Amount, error) {
+	whole, frac := s, ""
+
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+
+		if len(frac) == 0 || len(frac) > Decimals {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	if len(whole) == 0 || strings.Trim(whole+frac, "0123456789") != "" {
+		return 0, ErrInvalidAmount
+	}
+
+	for len(frac) < Decimals {
+		frac += "0"
+	}
+
+	v, err := strconv.ParseUint(whole+frac, 10, 64)
+	if err != nil || Amount(v) > Max {
+		return 0, ErrOverflow
+	}
+
+	return Amount(v), nil
+}
+
+// String formats a with exactly two fractional digits.
+func (a Amount) String() string {
+	s := strconv.FormatUint(uint64(a), 10)
+
+	for len(s) <= Decimals {
+		s = "0" + s
+	}
+
+	return s[:len(s)-Decimals] + "." + s[len(s)-Decimals:]
+}
+
+func (a Amount) Valid() bool {
+	return a <= Max
+}
+
+func (a Amount) Cmp(b Amount) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a > Max || b > Max-a {
+		return 0, ErrOverflow
+	}
+
+	return a + b, nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b > a {
+		return 0, ErrUnderflow
+	}
+
+	return a - b, nil
+}
+
+// MulPercent returns a*p/10000 rounded down, p is expressed in hundredths
+// of a percent like Transaction.ProfitPercent and FeePercent (100 is 1%).
+func (a Amount) MulPercent(p uint16) (Amount, error) {
+	if a > Max {
+		return 0, ErrOverflow
+	}
+
+	hi, lo := bits.Mul64(uint64(a), uint64(p))
+	q, _ := bits.Div64(hi, lo, 10000)
+
+	if Amount(q) > Max {
+		return 0, ErrOverflow
+	}
+
+	return Amount(q), nil
+}
+
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Amount) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = v
+
+	return nil
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return ErrInvalidAmount
+	}
+
+	return a.UnmarshalText([]byte(s))
+}
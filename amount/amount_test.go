@@ -0,0 +1,154 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package amount_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/quick"
+
+	"github.com/umi-top/umi-core/amount"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		str string
+		val amount.Amount
+		err error
+	}{
+		{"0", 0, nil},
+		{"0.01", 1, nil},
+		{"1.5", 150, nil},
+		{"12.34", 1234, nil},
+		{"007", 700, nil},
+		{"90071992547409.91", amount.Max, nil},
+		{"90071992547409.92", 0, amount.ErrOverflow},
+		{"99999999999999999999", 0, amount.ErrOverflow},
+		{"", 0, amount.ErrInvalidAmount},
+		{"1.", 0, amount.ErrInvalidAmount},
+		{".1", 0, amount.ErrInvalidAmount},
+		{"1.234", 0, amount.ErrInvalidAmount},
+		{"-1", 0, amount.ErrInvalidAmount},
+		{"+1", 0, amount.ErrInvalidAmount},
+		{"1e2", 0, amount.ErrInvalidAmount},
+		{"1,5", 0, amount.ErrInvalidAmount},
+	}
+
+	for _, tc := range cases {
+		act, err := amount.Parse(tc.str)
+		if !errors.Is(err, tc.err) || act != tc.val {
+			t.Errorf("For %q expected: %d %v, got: %d %v", tc.str, tc.val, tc.err, act, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := map[amount.Amount]string{
+		0:          "0.00",
+		1:          "0.01",
+		10:         "0.10",
+		1234:       "12.34",
+		amount.Max: "90071992547409.91",
+	}
+
+	for v, exp := range cases {
+		if act := v.String(); act != exp {
+			t.Errorf("For %d expected: %s, got: %s", v, exp, act)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	f := func(v uint64) bool {
+		a := amount.Amount(v) % (amount.Max + 1)
+		b, err := amount.Parse(a.String())
+
+		return err == nil && a == b
+	}
+
+	if err := quick.Check(f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	if v, err := amount.Amount(150).Add(250); err != nil || v != 400 {
+		t.Fatalf("Expected: 400, got: %d %v", v, err)
+	}
+
+	if _, err := amount.Max.Add(1); !errors.Is(err, amount.ErrOverflow) {
+		t.Fatalf("Expected: %v, got: %v", amount.ErrOverflow, err)
+	}
+
+	if v, err := amount.Amount(250).Sub(150); err != nil || v != 100 {
+		t.Fatalf("Expected: 100, got: %d %v", v, err)
+	}
+
+	if _, err := amount.Amount(1).Sub(2); !errors.Is(err, amount.ErrUnderflow) {
+		t.Fatalf("Expected: %v, got: %v", amount.ErrUnderflow, err)
+	}
+
+	if c := amount.Amount(1).Cmp(2); c != -1 {
+		t.Fatalf("Expected: -1, got: %d", c)
+	}
+}
+
+func TestMulPercent(t *testing.T) {
+	cases := []struct {
+		val amount.Amount
+		pct uint16
+		exp amount.Amount
+	}{
+		{10000, 100, 100},
+		{10000, 2000, 2000},
+		{199, 500, 9},
+		{1, 9999, 0},
+		{amount.Max, 10000, amount.Max},
+		{amount.Max, 500, 450359962737049},
+	}
+
+	for _, tc := range cases {
+		if act, err := tc.val.MulPercent(tc.pct); err != nil || act != tc.exp {
+			t.Errorf("For %d*%d expected: %d, got: %d %v", tc.val, tc.pct, tc.exp, act, err)
+		}
+	}
+
+	if _, err := amount.Max.MulPercent(10001); !errors.Is(err, amount.ErrOverflow) {
+		t.Fatalf("Expected: %v, got: %v", amount.ErrOverflow, err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b, _ := json.Marshal(struct{ V amount.Amount }{1234})
+	if string(b) != `{"V":"12.34"}` {
+		t.Fatalf("Expected: %s, got: %s", `{"V":"12.34"}`, b)
+	}
+
+	var v amount.Amount
+	if err := json.Unmarshal([]byte(`"0.5"`), &v); err != nil || v != 50 {
+		t.Fatalf("Expected: 50, got: %d %v", v, err)
+	}
+
+	if err := json.Unmarshal([]byte(`0.5`), &v); !errors.Is(err, amount.ErrInvalidAmount) {
+		t.Fatalf("Expected: %v, got: %v", amount.ErrInvalidAmount, err)
+	}
+}
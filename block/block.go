@@ -135,6 +135,28 @@ func (b *Block) Sign(k *key.SecretKey) *Block {
 	return b
 }
 
+// SignWith sets the public key of s and signs the header with it. On error
+// b is left unchanged.
+func (b *Block) SignWith(s key.Signer) error {
+	hdr := make([]byte, 103)
+	copy(hdr, b.Bytes[0:103])
+	copy(hdr[71:103], s.PublicKey().ToBytes())
+
+	sig, err := s.Sign(hdr)
+	if err != nil {
+		return err
+	}
+
+	if len(sig) != 64 {
+		return ErrInvalidSignature
+	}
+
+	copy(b.Bytes[71:103], s.PublicKey().ToBytes())
+	copy(b.Bytes[103:167], sig)
+
+	return nil
+}
+
 func (b *Block) Transaction(idx uint16) *transaction.Transaction {
 	offset := idx*transaction.Length + HeaderLength
 	return transaction.FromBytes(b.Bytes[offset : offset+transaction.Length])
@@ -148,5 +170,5 @@ func (b *Block) AppendTransaction(t *transaction.Transaction) *Block {
 }
 
 func (b *Block) Verify() bool {
-	return b.PublicKey().VerifySignature(b.Bytes[0:103], b.Bytes[103:167])
+	return b.PublicKey().VerifySignature(b.Bytes[103:167], b.Bytes[0:103])
 }
@@ -22,13 +22,16 @@ package block_test
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
 	"github.com/umi-top/umi-core/transaction"
 )
 
@@ -188,3 +191,48 @@ func TestMerkel(t *testing.T) {
 		t.Error("Expected", expected, "got", hex.EncodeToString(bl.MerkleRootHash()))
 	}
 }
+
+func TestVerify(t *testing.T) {
+	bl := block.FromBytes(blk)
+	if !bl.Verify() {
+		t.Error("Expected", true, "got", false)
+	}
+
+	bl.Bytes[68] ^= 1
+
+	if bl.Verify() {
+		t.Error("Expected", false, "got", true)
+	}
+}
+
+type badSigner struct {
+	key.Signer
+	sig []byte
+	err error
+}
+
+func (s badSigner) Sign([]byte) ([]byte, error) {
+	return s.sig, s.err
+}
+
+func TestSignWithError(t *testing.T) {
+	_, sec, _ := ed25519.GenerateKey(rand.Reader)
+	s := key.NewSigner(key.NewSecretKey(sec))
+
+	for _, bs := range []badSigner{{Signer: s, err: errors.New("agent failure")}, {Signer: s, sig: make([]byte, 63)}} {
+		b := block.FromBytes(blk)
+
+		if err := b.SignWith(bs); err == nil {
+			t.Fatalf("Expected: error, got: nil")
+		}
+
+		if !bytes.Equal(b.Bytes, blk) {
+			t.Fatalf("Expected: block to be unchanged")
+		}
+	}
+
+	b := block.FromBytes(blk)
+	if err := b.SignWith(s); err != nil || !b.Verify() {
+		t.Fatalf("Expected: signed block, got: %v", err)
+	}
+}
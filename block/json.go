@@ -0,0 +1,130 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package block
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/umi-top/umi-core/transaction"
+)
+
+var (
+	ErrInvalidJSON    = errors.New("block: invalid json")
+	ErrInvalidHash    = errors.New("block: invalid hash")
+	ErrInvalidTxCount = errors.New("block: invalid transaction count")
+)
+
+type jsonBlock struct {
+	Hash              string                     `json:"hash"`
+	Version           uint8                      `json:"version"`
+	PreviousBlockHash string                     `json:"previousBlockHash"`
+	MerkleRootHash    string                     `json:"merkleRootHash"`
+	Timestamp         uint32                     `json:"timestamp"`
+	TxCount           uint16                     `json:"txCount"`
+	PublicKey         string                     `json:"publicKey"`
+	Signature         string                     `json:"signature"`
+	Transactions      []*transaction.Transaction `json:"transactions"`
+}
+
+func (b *Block) MarshalJSON() ([]byte, error) {
+	if len(b.Bytes) != HeaderLength+int(b.TxCount())*transaction.Length {
+		return nil, ErrInvalidTxCount
+	}
+
+	j := jsonBlock{
+		Hash:              hex.EncodeToString(b.Hash()),
+		Version:           b.Version(),
+		PreviousBlockHash: hex.EncodeToString(b.PreviousBlockHash()),
+		MerkleRootHash:    hex.EncodeToString(b.MerkleRootHash()),
+		Timestamp:         b.Timestamp(),
+		TxCount:           b.TxCount(),
+		PublicKey:         hex.EncodeToString(b.Bytes[71:103]),
+		Signature:         hex.EncodeToString(b.Signature()),
+		Transactions:      make([]*transaction.Transaction, 0, b.TxCount()),
+	}
+
+	for i := uint16(0); i < b.TxCount(); i++ {
+		j.Transactions = append(j.Transactions, b.Transaction(i))
+	}
+
+	return json.Marshal(j)
+}
+
+func (b *Block) UnmarshalJSON(data []byte) error {
+	var j jsonBlock
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&j); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	if int(j.TxCount) != len(j.Transactions) {
+		return ErrInvalidTxCount
+	}
+
+	q := NewBlock()
+	q.SetVersion(j.Version)
+	q.SetTimestamp(j.Timestamp)
+
+	for _, f := range []struct {
+		val string
+		dst []byte
+	}{
+		{j.PreviousBlockHash, q.Bytes[1:33]},
+		{j.MerkleRootHash, q.Bytes[33:65]},
+		{j.PublicKey, q.Bytes[71:103]},
+		{j.Signature, q.Bytes[103:167]},
+	} {
+		v, err := hex.DecodeString(f.val)
+		if err != nil || len(v) != len(f.dst) {
+			return fmt.Errorf("%w: malformed hex field", ErrInvalidJSON)
+		}
+
+		copy(f.dst, v)
+	}
+
+	for _, t := range j.Transactions {
+		if t == nil {
+			return fmt.Errorf("%w: null transaction", ErrInvalidJSON)
+		}
+
+		q.AppendTransaction(t)
+	}
+
+	hsh, err := hex.DecodeString(j.Hash)
+	if err != nil || !bytes.Equal(hsh, q.Hash()) {
+		return ErrInvalidHash
+	}
+
+	if !bytes.Equal(q.MerkleRootHash(), q.CalculateMerkleRoot()) {
+		return ErrInvalidMerkleRoot
+	}
+
+	b.Bytes = q.Bytes
+
+	return nil
+}
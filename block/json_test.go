@@ -0,0 +1,116 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package block_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/umi-top/umi-core/block"
+)
+
+// merkleBlock is blk with a merkle root that matches its transactions,
+// the root stored in blk does not.
+func merkleBlock() *block.Block {
+	b := block.FromBytes(blk)
+	b.SetMerkleRootHash(b.CalculateMerkleRoot())
+
+	return b
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	b, err := json.Marshal(block.FromBytes(blk))
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if !strings.Contains(string(b), `"hash":"`+hex.EncodeToString(hshz)+`"`) {
+		t.Fatalf("Expected block hash in %s", b)
+	}
+
+	exp := merkleBlock()
+	b, _ = json.Marshal(exp)
+
+	act := &block.Block{}
+	if err := json.Unmarshal(b, act); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if !bytes.Equal(exp.Bytes, act.Bytes) {
+		t.Fatalf("Expected: %x, got: %x", exp.Bytes, act.Bytes)
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	b, _ := json.Marshal(merkleBlock())
+
+	var m map[string]interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	_ = dec.Decode(&m)
+
+	cases := []struct {
+		desc string
+		edit func(map[string]interface{})
+		err  error
+	}{
+		{"stale merkle root", func(m map[string]interface{}) {
+			m["merkleRootHash"] = hex.EncodeToString(blk[33:65])
+			m["hash"] = hex.EncodeToString(hshz)
+		}, block.ErrInvalidMerkleRoot},
+		{"tampered timestamp", func(m map[string]interface{}) { m["timestamp"] = 1 }, block.ErrInvalidHash},
+		{"tx count", func(m map[string]interface{}) { m["txCount"] = 3 }, block.ErrInvalidTxCount},
+		{"unknown field", func(m map[string]interface{}) { m["height"] = 1 }, block.ErrInvalidJSON},
+		{"malformed hex", func(m map[string]interface{}) { m["publicKey"] = "xyz" }, block.ErrInvalidJSON},
+		{"dropped tx", func(m map[string]interface{}) {
+			m["transactions"] = m["transactions"].([]interface{})[:1]
+			m["txCount"] = 1
+		}, block.ErrInvalidHash},
+		{"swapped txs", func(m map[string]interface{}) {
+			txs := append([]interface{}{}, m["transactions"].([]interface{})...)
+			txs[0], txs[1] = txs[1], txs[0]
+			m["transactions"] = txs
+		}, block.ErrInvalidMerkleRoot},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			c := make(map[string]interface{})
+			for k, v := range m {
+				c[k] = v
+			}
+
+			tc.edit(c)
+			b, _ := json.Marshal(c)
+
+			err := json.Unmarshal(b, &block.Block{})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
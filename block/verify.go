@@ -0,0 +1,113 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package block
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/network"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+var (
+	ErrInvalidVersion    = errors.New("block: invalid version")
+	ErrInvalidMerkleRoot = errors.New("block: invalid merkle root hash")
+	ErrInvalidSignature  = errors.New("block: invalid signature")
+	ErrInvalidGenesis    = errors.New("block: invalid genesis block")
+)
+
+// VerifyWithParams checks the header, the merkle root and every
+// transaction of b against p. Genesis transactions are only accepted in a
+// block with a zero previous block hash and must be sent from the genesis
+// address of the block key. Such a block must match the genesis hash of p
+// when one is pinned.
+func (b *Block) VerifyWithParams(p *network.Params) error {
+	if len(b.Bytes) < HeaderLength || len(b.Bytes) != HeaderLength+int(b.TxCount())*transaction.Length {
+		return ErrInvalidTxCount
+	}
+
+	if b.Version() != p.BlockVersion {
+		return ErrInvalidVersion
+	}
+
+	genesis := bytes.Equal(b.PreviousBlockHash(), make([]byte, 32))
+
+	if genesis && p.GenesisHash != nil && !bytes.Equal(b.Hash(), p.GenesisHash) {
+		return ErrInvalidGenesis
+	}
+
+	if !bytes.Equal(b.MerkleRootHash(), b.CalculateMerkleRoot()) {
+		return ErrInvalidMerkleRoot
+	}
+
+	if !b.Verify() {
+		return ErrInvalidSignature
+	}
+
+	for i := uint16(0); i < b.TxCount(); i++ {
+		t := b.Transaction(i)
+
+		var err error
+
+		switch {
+		case t.Version() != transaction.Genesis:
+			err = t.VerifyWithParams(p)
+		case genesis:
+			err = b.verifyGenesis(t, p)
+		default:
+			err = transaction.ErrInvalidVersion
+		}
+
+		if err != nil {
+			return fmt.Errorf("block: transaction %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// verifyGenesis checks a Genesis transaction of the genesis block b: it
+// must be signed by the genesis address of the block key and allocate a
+// value within the cap of p to a non-genesis address.
+func (b *Block) verifyGenesis(t *transaction.Transaction, p *network.Params) error {
+	snd, rcp := t.Sender(), t.Recipient()
+
+	if snd.Version() != address.Genesis || !bytes.Equal(snd.PublicKey().ToBytes(), b.PublicKey().ToBytes()) {
+		return ErrInvalidGenesis
+	}
+
+	if t.Amount() == 0 || !t.Amount().Valid() || t.Amount() > p.MaxValue {
+		return transaction.ErrInvalidValue
+	}
+
+	if rcp.Verify() != nil || rcp.Version() == address.Genesis {
+		return transaction.ErrInvalidRecipient
+	}
+
+	if !snd.PublicKey().VerifySignature(t.Signature(), t.Bytes[0:85]) {
+		return transaction.ErrInvalidSignature
+	}
+
+	return nil
+}
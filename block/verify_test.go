@@ -0,0 +1,150 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package block_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/network"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newBlock(prev byte, txVer uint8) (*block.Block, *key.SecretKey) {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	k := key.NewSecretKey(sec)
+
+	snd := address.FromKey(k)
+	if txVer == transaction.Genesis {
+		snd.SetPrefix("genesis")
+	}
+
+	tx := transaction.NewTransaction().SetVersion(txVer).SetSender(snd).
+		SetRecipient(address.FromKey(k).SetPrefix("aaa")).SetValue(100).Sign(*k)
+
+	b := block.NewBlock().SetPreviousBlockHash([]byte{prev}).AppendTransaction(tx)
+	b.SetMerkleRootHash(b.CalculateMerkleRoot()).Sign(k)
+
+	return b, k
+}
+
+func TestVerifyWithParams(t *testing.T) {
+	b, k := newBlock(1, transaction.Basic)
+	if err := b.VerifyWithParams(network.Mainnet()); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	p, _ := network.NewDevnet("local").SetBlockVersion(2).SetMaxValue(99).Build()
+
+	if err := b.VerifyWithParams(p); !errors.Is(err, block.ErrInvalidVersion) {
+		t.Fatalf("Expected: %v, got: %v", block.ErrInvalidVersion, err)
+	}
+
+	b.SetVersion(2).Sign(k)
+
+	if err := b.VerifyWithParams(p); !errors.Is(err, transaction.ErrInvalidValue) {
+		t.Fatalf("Expected: %v, got: %v", transaction.ErrInvalidValue, err)
+	}
+
+	b.SetMerkleRootHash(make([]byte, 32)).Sign(k)
+
+	if err := b.VerifyWithParams(p); !errors.Is(err, block.ErrInvalidMerkleRoot) {
+		t.Fatalf("Expected: %v, got: %v", block.ErrInvalidMerkleRoot, err)
+	}
+
+	b.SetMerkleRootHash(b.CalculateMerkleRoot())
+	b.SetTimestamp(1)
+
+	if err := b.VerifyWithParams(p); !errors.Is(err, block.ErrInvalidSignature) {
+		t.Fatalf("Expected: %v, got: %v", block.ErrInvalidSignature, err)
+	}
+
+	b.Bytes = b.Bytes[:len(b.Bytes)-1]
+
+	if err := b.VerifyWithParams(network.Mainnet()); !errors.Is(err, block.ErrInvalidTxCount) {
+		t.Fatalf("Expected: %v, got: %v", block.ErrInvalidTxCount, err)
+	}
+}
+
+func TestVerifyWithParamsGenesis(t *testing.T) {
+	b, _ := newBlock(1, transaction.Genesis)
+	if err := b.VerifyWithParams(network.Mainnet()); !errors.Is(err, transaction.ErrInvalidVersion) {
+		t.Fatalf("Expected: %v, got: %v", transaction.ErrInvalidVersion, err)
+	}
+
+	b, _ = newBlock(0, transaction.Genesis)
+	if err := b.VerifyWithParams(network.Mainnet()); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	p, _ := network.NewDevnet("local").SetGenesisHash(b.Hash()).Build()
+	if err := b.VerifyWithParams(p); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	o, _ := newBlock(0, transaction.Genesis)
+	if err := o.VerifyWithParams(p); !errors.Is(err, block.ErrInvalidGenesis) {
+		t.Fatalf("Expected: %v, got: %v", block.ErrInvalidGenesis, err)
+	}
+}
+
+func TestVerifyWithParamsGenesisTransaction(t *testing.T) {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	k := key.NewSecretKey(sec)
+	_, other, _ := ed25519.GenerateKey(nil)
+	o := key.NewSecretKey(other)
+
+	newTx := func(snd *key.SecretKey, pfx string, v amount.Amount) *transaction.Transaction {
+		return transaction.NewTransaction().SetVersion(transaction.Genesis).
+			SetSender(address.FromKey(snd).SetPrefix(pfx)).
+			SetRecipient(address.FromKey(o)).SetAmount(v).Sign(*snd)
+	}
+
+	forged := newTx(k, "genesis", 100)
+	forged.Bytes[85] ^= 1
+
+	cases := []struct {
+		desc string
+		tx   *transaction.Transaction
+		err  error
+	}{
+		{"valid", newTx(k, "genesis", 100), nil},
+		{"other key", newTx(o, "genesis", 100), block.ErrInvalidGenesis},
+		{"not genesis sender", newTx(k, "umi", 100), block.ErrInvalidGenesis},
+		{"zero value", newTx(k, "genesis", 0), transaction.ErrInvalidValue},
+		{"over cap", newTx(k, "genesis", amount.Max+1), transaction.ErrInvalidValue},
+		{"signature", forged, transaction.ErrInvalidSignature},
+	}
+
+	for _, tc := range cases {
+		b := block.NewBlock().AppendTransaction(tc.tx)
+		b.SetMerkleRootHash(b.CalculateMerkleRoot()).Sign(k)
+
+		if err := b.VerifyWithParams(network.Mainnet()); !errors.Is(err, tc.err) {
+			t.Fatalf("%s: Expected: %v, got: %v", tc.desc, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Command umi-vanity searches for a key whose address matches a pattern.
+//
+//	umi-vanity -start umi -end 42
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/vanity"
+)
+
+func main() {
+	var (
+		p       vanity.Pattern
+		workers int
+		timeout time.Duration
+		quiet   bool
+	)
+
+	flag.StringVar(&p.Prefix, "prefix", "umi", "address prefix")
+	flag.StringVar(&p.Start, "start", "", "characters right after the separator")
+	flag.StringVar(&p.Contains, "contains", "", "characters anywhere after the separator")
+	flag.StringVar(&p.End, "end", "", "last characters of the address")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "number of parallel workers")
+	flag.DurationVar(&timeout, "timeout", 0, "give up after this long, 0 means never")
+	flag.BoolVar(&quiet, "quiet", false, "do not report progress")
+	flag.Parse()
+
+	if err := p.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+
+	go func() {
+		<-sig
+		cancel()
+	}()
+
+	fmt.Fprintf(os.Stderr, "expected attempts: %.0f\n", p.Difficulty())
+
+	opt := vanity.Options{Workers: workers}
+	if !quiet {
+		opt.Progress = func(pr vanity.Progress) {
+			fmt.Fprintf(os.Stderr, "%d attempts, %.0f/s, %.1f%% chance so far\n",
+				pr.Attempts, pr.Rate, pr.Probability*100)
+		}
+	}
+
+	k, err := vanity.Search(ctx, p, opt)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println("address:   ", address.FromKey(k).SetPrefix(p.Prefix).ToBech32())
+	fmt.Println("secret key:", base64.StdEncoding.EncodeToString(k.ToBytes()))
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package devnet
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+type response struct {
+	Data  interface{}    `json:"data,omitempty"`
+	Error *responseError `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonAccount struct {
+	Address            string `json:"address"`
+	ConfirmedBalance   uint64 `json:"confirmedBalance"`
+	UnconfirmedBalance uint64 `json:"unconfirmedBalance"`
+}
+
+type jsonBlock struct {
+	Height int          `json:"height"`
+	Data   string       `json:"data"`
+	Block  *block.Block `json:"block"`
+}
+
+type jsonBlockchain struct {
+	Height        int    `json:"height"`
+	LastBlockHash string `json:"lastBlockHash"`
+}
+
+// Handler serves the node API:
+//
+//	POST /api/mempool                       {"data": "<base64 transaction>"}
+//	GET  /api/addresses/{address}/account
+//	GET  /api/blockchain
+//	GET  /api/blocks/{height}
+//	POST /api/devnet/blocks                 confirms the mempool now
+//
+// Responses are wrapped in {"data": ...} or {"error": {"code", "message"}}.
+func (n *Node) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/mempool", n.handleMempool)
+	mux.HandleFunc("/api/addresses/", n.handleAccount)
+	mux.HandleFunc("/api/blockchain", n.handleBlockchain)
+	mux.HandleFunc("/api/blocks/", n.handleBlock)
+	mux.HandleFunc("/api/devnet/blocks", n.handleMine)
+
+	return mux
+}
+
+func (n *Node) handleMempool(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Data string `json:"data"`
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	b, err := base64.StdEncoding.DecodeString(req.Data)
+	if err != nil || len(b) != transaction.Length {
+		writeError(w, http.StatusBadRequest, "invalid transaction")
+		return
+	}
+
+	t := transaction.FromBytes(b)
+
+	if err := n.Submit(t); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrDuplicate) {
+			status = http.StatusConflict
+		}
+
+		writeError(w, status, err.Error())
+
+		return
+	}
+
+	writeData(w, http.StatusAccepted, map[string]string{"hash": hex.EncodeToString(t.Hash())})
+}
+
+func (n *Node) handleAccount(w http.ResponseWriter, r *http.Request) {
+	s := strings.TrimPrefix(r.URL.Path, "/api/addresses/")
+	if r.Method != http.MethodGet || !strings.HasSuffix(s, "/account") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	a, err := address.ParseBech32(strings.TrimSuffix(s, "/account"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeData(w, http.StatusOK, jsonAccount{
+		Address:            a.ToBech32(),
+		ConfirmedBalance:   uint64(n.Balance(a)),
+		UnconfirmedBalance: uint64(n.UnconfirmedBalance(a)),
+	})
+}
+
+func (n *Node) handleBlockchain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	writeData(w, http.StatusOK, n.blockchain())
+}
+
+func (n *Node) handleBlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	h, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/blocks/"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid height")
+		return
+	}
+
+	b, err := n.Block(h)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeData(w, http.StatusOK, jsonBlock{Height: h, Data: base64.StdEncoding.EncodeToString(b.Bytes), Block: b})
+}
+
+func (n *Node) handleMine(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	b, err := n.Mine()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status := http.StatusCreated
+	if b == nil {
+		status = http.StatusOK
+	}
+
+	writeData(w, status, n.blockchain())
+}
+
+func (n *Node) blockchain() jsonBlockchain {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return jsonBlockchain{Height: len(n.blocks), LastBlockHash: hex.EncodeToString(n.blocks[len(n.blocks)-1].Hash())}
+}
+
+func writeData(w http.ResponseWriter, status int, v interface{}) {
+	write(w, status, response{Data: v})
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	write(w, status, response{Error: &responseError{Code: status, Message: msg}})
+}
+
+func write(w http.ResponseWriter, status int, v response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
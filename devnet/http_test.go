@@ -0,0 +1,113 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package devnet_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+)
+
+func request(t *testing.T, srv *httptest.Server, method, path, body string, status int) map[string]interface{} {
+	req, _ := http.NewRequest(method, srv.URL+path, bytes.NewBufferString(body))
+
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != status {
+		t.Fatalf("%s %s: Expected: %d, got: %d", method, path, status, res.StatusCode)
+	}
+
+	var v map[string]interface{}
+
+	dec := json.NewDecoder(res.Body)
+	dec.UseNumber()
+
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	return v
+}
+
+func TestHTTP(t *testing.T) {
+	sec := newKey()
+	rcp := address.FromKey(newKey())
+	n := newNode(t, sec, 1000)
+
+	srv := httptest.NewServer(n.Handler())
+	defer srv.Close()
+
+	tx := transfer(sec, rcp, 250, 1)
+	body := `{"data":"` + base64.StdEncoding.EncodeToString(tx.Bytes) + `"}`
+
+	v := request(t, srv, http.MethodPost, "/api/mempool", body, http.StatusAccepted)
+	if act := v["data"].(map[string]interface{})["hash"]; act != hex.EncodeToString(tx.Hash()) {
+		t.Fatalf("Expected: %s, got: %v", hex.EncodeToString(tx.Hash()), act)
+	}
+
+	v = request(t, srv, http.MethodPost, "/api/mempool", body, http.StatusConflict)
+	if v["error"].(map[string]interface{})["code"] != json.Number("409") {
+		t.Fatalf("Expected: error code 409, got: %v", v)
+	}
+
+	request(t, srv, http.MethodPost, "/api/mempool", `{"data":"AAAA"}`, http.StatusBadRequest)
+	request(t, srv, http.MethodPost, "/api/devnet/blocks", "", http.StatusCreated)
+	request(t, srv, http.MethodPost, "/api/devnet/blocks", "", http.StatusOK)
+
+	v = request(t, srv, http.MethodGet, "/api/addresses/"+address.FromKey(sec).ToBech32()+"/account", "", http.StatusOK)
+	acc := v["data"].(map[string]interface{})
+
+	if acc["confirmedBalance"] != json.Number("750") || acc["unconfirmedBalance"] != json.Number("750") {
+		t.Fatalf("Expected: 750, got: %v", acc)
+	}
+
+	request(t, srv, http.MethodGet, "/api/addresses/umi1xyz/account", "", http.StatusBadRequest)
+
+	v = request(t, srv, http.MethodGet, "/api/blockchain", "", http.StatusOK)
+	if act := v["data"].(map[string]interface{})["height"]; act != json.Number("2") {
+		t.Fatalf("Expected: 2, got: %v", act)
+	}
+
+	b := mustBlock(t, n, 2)
+
+	v = request(t, srv, http.MethodGet, "/api/blocks/2", "", http.StatusOK)
+	if act := v["data"].(map[string]interface{})["data"]; act != base64.StdEncoding.EncodeToString(b.Bytes) {
+		t.Fatalf("Expected: %s, got: %v", base64.StdEncoding.EncodeToString(b.Bytes), act)
+	}
+
+	if act := v["data"].(map[string]interface{})["block"].(map[string]interface{})["hash"]; act != hex.EncodeToString(b.Hash()) {
+		t.Fatalf("Expected: %s, got: %v", hex.EncodeToString(b.Hash()), act)
+	}
+
+	request(t, srv, http.MethodGet, "/api/blocks/3", "", http.StatusNotFound)
+	request(t, srv, http.MethodGet, "/api/blocks/x", "", http.StatusBadRequest)
+	request(t, srv, http.MethodGet, "/api/mempool", "", http.StatusMethodNotAllowed)
+}
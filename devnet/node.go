@@ -0,0 +1,357 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package devnet runs an in-process UMI network for integration tests.
+//
+// A Node starts from a genesis block, accepts signed transactions into a
+// mempool, confirms them in signed blocks either on demand or on a timer,
+// keeps balances and serves them over the HTTP API of a real node.
+package devnet
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/umi-top/umi-core/accounting"
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/genesis"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/network"
+	"github.com/umi-top/umi-core/structure"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+var (
+	ErrDuplicate         = errors.New("devnet: duplicate transaction")
+	ErrNonCanonical      = errors.New("devnet: non-canonical transaction")
+	ErrInvalidNonce      = errors.New("devnet: nonce is not above the last nonce of the sender")
+	ErrInsufficientFunds = errors.New("devnet: insufficient funds")
+	ErrMempoolFull       = errors.New("devnet: mempool is full")
+	ErrNotFound          = errors.New("devnet: block not found")
+)
+
+// Config describes a devnet. A nil Params means network.Mainnet(), a nil Key
+// is generated. Blocks are only produced on demand when Interval is zero.
+type Config struct {
+	Params      *network.Params
+	Key         *key.SecretKey
+	Allocations []genesis.Allocation
+	Interval    time.Duration
+	Now         func() time.Time
+}
+
+type Node struct {
+	params   *network.Params
+	key      *key.SecretKey
+	now      func() time.Time
+	interval time.Duration
+
+	mu       sync.RWMutex
+	registry *structure.Registry
+	ledger   *accounting.Ledger
+	blocks   []*block.Block
+	mempool  []*transaction.Transaction
+	pending  map[string]amount.Amount
+	seen     map[string]struct{}
+	// nonces holds the last confirmed nonce of each sender, pendingNonces
+	// the last one in the mempool.
+	nonces        map[string]uint64
+	pendingNonces map[string]uint64
+
+	// run guards stop and done. It is separate from mu, Stop holds it while
+	// the mining goroutine finishes a block under mu.
+	run  sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+func New(c Config) (*Node, error) {
+	n := &Node{
+		params:   c.Params,
+		key:      c.Key,
+		now:      c.Now,
+		interval: c.Interval,
+		registry: structure.NewRegistry(),
+		pending:  make(map[string]amount.Amount),
+		seen:     make(map[string]struct{}),
+
+		nonces:        make(map[string]uint64),
+		pendingNonces: make(map[string]uint64),
+	}
+
+	if n.params == nil {
+		n.params = network.Mainnet()
+	}
+
+	if n.key == nil {
+		_, sec, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		n.key = key.NewSecretKey(sec)
+	}
+
+	if n.now == nil {
+		n.now = time.Now
+	}
+
+	n.ledger = accounting.NewLedger(n.registry)
+
+	g, err := genesis.NewBlock(genesis.Config{
+		Timestamp:   uint32(n.now().Unix()),
+		Allocations: c.Allocations,
+		Key:         n.key,
+		Params:      n.params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.ledger.ApplyBlock(g); err != nil {
+		return nil, err
+	}
+
+	n.blocks = append(n.blocks, g)
+
+	return n, nil
+}
+
+func (n *Node) Params() *network.Params {
+	return n.params
+}
+
+// Start produces a block every Interval until Stop is called.
+func (n *Node) Start() {
+	n.run.Lock()
+	defer n.run.Unlock()
+
+	if n.interval <= 0 || n.stop != nil {
+		return
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	n.stop, n.done = stop, done
+
+	go func() {
+		defer close(done)
+
+		t := time.NewTicker(n.interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				_, _ = n.Mine()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (n *Node) Stop() {
+	n.run.Lock()
+	defer n.run.Unlock()
+
+	if n.stop == nil {
+		return
+	}
+
+	close(n.stop)
+	<-n.done
+	n.stop, n.done = nil, nil
+}
+
+// Submit validates t and adds it to the mempool. Transfers must be covered
+// by the confirmed balance of the sender less its pending transfers. The
+// nonce must be above the last confirmed or pending nonce of the sender,
+// and the unsigned last byte must be zero, so a transaction can not be
+// replayed.
+func (n *Node) Submit(t *transaction.Transaction) error {
+	if t.Bytes[transaction.Length-1] != 0 {
+		return ErrNonCanonical
+	}
+
+	if err := t.VerifyWithParams(n.params); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.registry.CheckTransfer(t); err != nil {
+		return err
+	}
+
+	h := signed(t)
+	if _, ok := n.seen[h]; ok {
+		return ErrDuplicate
+	}
+
+	snd := string(t.Sender().Bytes)
+
+	last, ok := n.pendingNonces[snd]
+	if !ok {
+		last, ok = n.nonces[snd]
+	}
+
+	if ok && t.Nonce() <= last {
+		return ErrInvalidNonce
+	}
+
+	if len(n.mempool) == math.MaxUint16 {
+		return ErrMempoolFull
+	}
+
+	if t.Version() == transaction.Basic {
+		p, err := n.pending[snd].Add(t.Amount())
+		if err != nil || p > n.balance(t.Sender()) {
+			return ErrInsufficientFunds
+		}
+
+		n.pending[snd] = p
+	}
+
+	n.seen[h] = struct{}{}
+	n.pendingNonces[snd] = t.Nonce()
+	n.mempool = append(n.mempool, transaction.FromBytes(t.Bytes))
+
+	return nil
+}
+
+// Mine confirms the mempool in a new block. Transactions that became
+// invalid since they were submitted are dropped. Mine returns nil when
+// there is nothing to confirm.
+func (n *Node) Mine() (*block.Block, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.mempool) == 0 {
+		return nil, nil
+	}
+
+	ts := uint32(n.now().Unix())
+	b := block.NewBlock().SetVersion(n.params.BlockVersion).SetPreviousBlockHash(n.blocks[len(n.blocks)-1].Hash())
+	b.SetTimestamp(ts)
+
+	for _, t := range n.mempool {
+		if n.confirm(t, ts) != nil {
+			delete(n.seen, signed(t))
+			continue
+		}
+
+		b.AppendTransaction(t)
+	}
+
+	n.mempool = nil
+	n.pending = make(map[string]amount.Amount)
+	n.pendingNonces = make(map[string]uint64)
+
+	if b.TxCount() == 0 {
+		return nil, nil
+	}
+
+	b.SetMerkleRootHash(b.CalculateMerkleRoot())
+	b.Sign(n.key)
+	n.blocks = append(n.blocks, b)
+
+	return block.FromBytes(b.Bytes), nil
+}
+
+func (n *Node) confirm(t *transaction.Transaction, ts uint32) error {
+	if err := n.registry.CheckTransfer(t); err != nil {
+		return err
+	}
+
+	snd := string(t.Sender().Bytes)
+	if last, ok := n.nonces[snd]; ok && t.Nonce() <= last {
+		return ErrInvalidNonce
+	}
+
+	if t.Version() == transaction.Basic && t.Amount() > n.balance(t.Sender()) {
+		return ErrInsufficientFunds
+	}
+
+	if err := n.ledger.Apply(t, ts); err != nil {
+		return err
+	}
+
+	n.nonces[snd] = t.Nonce()
+
+	return nil
+}
+
+// signed returns the part of t covered by the signature, the last byte is
+// not.
+func signed(t *transaction.Transaction) string {
+	return string(t.Bytes[0:85])
+}
+
+// Height returns the number of blocks, the genesis block has height 1.
+func (n *Node) Height() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return len(n.blocks)
+}
+
+func (n *Node) Block(height int) (*block.Block, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if height < 1 || height > len(n.blocks) {
+		return nil, ErrNotFound
+	}
+
+	return block.FromBytes(n.blocks[height-1].Bytes), nil
+}
+
+// Balance returns the confirmed balance of a.
+func (n *Node) Balance(a *address.Address) amount.Amount {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.balance(a)
+}
+
+// UnconfirmedBalance returns the confirmed balance of a less its pending
+// transfers.
+func (n *Node) UnconfirmedBalance(a *address.Address) amount.Amount {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.balance(a) - n.pending[string(a.Bytes)]
+}
+
+func (n *Node) balance(a *address.Address) amount.Amount {
+	b, err := n.ledger.Credited(a).Sub(n.ledger.Debited(a))
+	if err != nil {
+		return 0
+	}
+
+	return b
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package devnet_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/devnet"
+	"github.com/umi-top/umi-core/genesis"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/network"
+	"github.com/umi-top/umi-core/structure"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func newNode(t *testing.T, k *key.SecretKey, v amount.Amount) *devnet.Node {
+	n, err := devnet.New(devnet.Config{
+		Allocations: []genesis.Allocation{{Address: address.FromKey(k), Value: v}},
+	})
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	return n
+}
+
+func transfer(k *key.SecretKey, rcp *address.Address, v amount.Amount, nonce uint64) *transaction.Transaction {
+	return transaction.NewTransaction().SetSender(address.FromKey(k)).SetRecipient(rcp).
+		SetAmount(v).SetNonce(nonce).Sign(*k)
+}
+
+func TestNode(t *testing.T) {
+	sec := newKey()
+	snd, rcp := address.FromKey(sec), address.FromKey(newKey())
+	n := newNode(t, sec, 1000)
+
+	if err := genesis.Verify(mustBlock(t, n, 1)); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if act := n.Balance(snd); act != 1000 {
+		t.Fatalf("Expected: 1000, got: %d", act)
+	}
+
+	if err := n.Submit(transfer(sec, rcp, 600, 1)); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := n.Submit(transfer(sec, rcp, 600, 1)); !errors.Is(err, devnet.ErrDuplicate) {
+		t.Fatalf("Expected: %v, got: %v", devnet.ErrDuplicate, err)
+	}
+
+	if err := n.Submit(transfer(sec, rcp, 401, 2)); !errors.Is(err, devnet.ErrInsufficientFunds) {
+		t.Fatalf("Expected: %v, got: %v", devnet.ErrInsufficientFunds, err)
+	}
+
+	if err := n.Submit(transfer(sec, snd, 1, 3)); !errors.Is(err, transaction.ErrInvalidRecipient) {
+		t.Fatalf("Expected: %v, got: %v", transaction.ErrInvalidRecipient, err)
+	}
+
+	if act := n.UnconfirmedBalance(snd); act != 400 {
+		t.Fatalf("Expected: 400, got: %d", act)
+	}
+
+	if act := n.Balance(rcp); act != 0 {
+		t.Fatalf("Expected: 0, got: %d", act)
+	}
+
+	b, err := n.Mine()
+	if err != nil || b == nil {
+		t.Fatalf("Expected: block, got: %v, %v", b, err)
+	}
+
+	if err := b.VerifyWithParams(network.Mainnet()); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if n.Height() != 2 || b.TxCount() != 1 {
+		t.Fatalf("Expected: height 2 with 1 transaction, got: %d, %d", n.Height(), b.TxCount())
+	}
+
+	if act := n.Balance(snd); act != 400 {
+		t.Fatalf("Expected: 400, got: %d", act)
+	}
+
+	if act := n.Balance(rcp); act != 600 {
+		t.Fatalf("Expected: 600, got: %d", act)
+	}
+
+	if b, err := n.Mine(); b != nil || err != nil {
+		t.Fatalf("Expected: nil, nil, got: %v, %v", b, err)
+	}
+
+	if _, err := n.Block(3); !errors.Is(err, devnet.ErrNotFound) {
+		t.Fatalf("Expected: %v, got: %v", devnet.ErrNotFound, err)
+	}
+}
+
+func TestNodeReplay(t *testing.T) {
+	sec := newKey()
+	snd, rcp := address.FromKey(sec), address.FromKey(newKey())
+	n := newNode(t, sec, 1000)
+
+	tx := transfer(sec, rcp, 100, 5)
+	if err := n.Submit(tx); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	_, _ = n.Mine()
+
+	// The last byte is not covered by the signature.
+	for i := 1; i <= 3; i++ {
+		c := transaction.FromBytes(tx.Bytes)
+		c.Bytes[transaction.Length-1] = byte(i)
+
+		if err := n.Submit(c); !errors.Is(err, devnet.ErrNonCanonical) {
+			t.Fatalf("Expected: %v, got: %v", devnet.ErrNonCanonical, err)
+		}
+	}
+
+	if err := n.Submit(tx); !errors.Is(err, devnet.ErrDuplicate) {
+		t.Fatalf("Expected: %v, got: %v", devnet.ErrDuplicate, err)
+	}
+
+	if err := n.Submit(transfer(sec, rcp, 100, 5)); !errors.Is(err, devnet.ErrDuplicate) {
+		t.Fatalf("Expected: %v, got: %v", devnet.ErrDuplicate, err)
+	}
+
+	if err := n.Submit(transfer(sec, rcp, 101, 5)); !errors.Is(err, devnet.ErrInvalidNonce) {
+		t.Fatalf("Expected: %v, got: %v", devnet.ErrInvalidNonce, err)
+	}
+
+	if err := n.Submit(transfer(sec, rcp, 100, 6)); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := n.Submit(transfer(sec, rcp, 100, 6)); !errors.Is(err, devnet.ErrDuplicate) {
+		t.Fatalf("Expected: %v, got: %v", devnet.ErrDuplicate, err)
+	}
+
+	if err := n.Submit(transfer(sec, rcp, 1, 6)); !errors.Is(err, devnet.ErrInvalidNonce) {
+		t.Fatalf("Expected: %v, got: %v", devnet.ErrInvalidNonce, err)
+	}
+
+	_, _ = n.Mine()
+
+	if act := n.Balance(snd); act != 800 {
+		t.Fatalf("Expected: 800, got: %d", act)
+	}
+}
+
+func TestNodeStructures(t *testing.T) {
+	sec, own := newKey(), newKey()
+	n := newNode(t, sec, 10000)
+
+	tx := transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).
+		SetSender(address.FromKey(own)).SetPrefix("aaa").SetProfitPercent(500).Sign(*own)
+
+	if err := n.Submit(tx); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	// Unknown until the structure is confirmed.
+	usr := address.FromKey(newKey()).SetPrefix("aaa")
+	if err := n.Submit(transfer(sec, usr, 10000, 1)); !errors.Is(err, structure.ErrNotFound) {
+		t.Fatalf("Expected: %v, got: %v", structure.ErrNotFound, err)
+	}
+
+	_, _ = n.Mine()
+
+	if err := n.Submit(transfer(sec, usr, 10000, 1)); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	_, _ = n.Mine()
+
+	if act := n.Balance(usr); act != 9500 {
+		t.Fatalf("Expected: 9500, got: %d", act)
+	}
+
+	if act := n.Balance(address.FromKey(own).SetPrefix("aaa")); act != 500 {
+		t.Fatalf("Expected: 500, got: %d", act)
+	}
+}
+
+func TestNodeDropsInvalid(t *testing.T) {
+	sec, own := newKey(), newKey()
+	n := newNode(t, sec, 100)
+
+	// The update is signed by someone who does not own "aaa".
+	create := transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).
+		SetSender(address.FromKey(own)).SetPrefix("aaa").SetProfitPercent(500).Sign(*own)
+	update := transaction.NewTransaction().SetVersion(transaction.UpdateSmartContract).
+		SetSender(address.FromKey(sec)).SetPrefix("aaa").SetProfitPercent(100).Sign(*sec)
+
+	_ = n.Submit(create)
+	_ = n.Submit(update)
+
+	b, _ := n.Mine()
+	if b.TxCount() != 1 {
+		t.Fatalf("Expected: 1, got: %d", b.TxCount())
+	}
+
+	// A dropped transaction may be submitted again.
+	if err := n.Submit(update); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+}
+
+func TestNodeInterval(t *testing.T) {
+	sec := newKey()
+	n, _ := devnet.New(devnet.Config{
+		Allocations: []genesis.Allocation{{Address: address.FromKey(sec), Value: 100}},
+		Interval:    time.Millisecond,
+	})
+
+	n.Start()
+	defer n.Stop()
+
+	_ = n.Submit(transfer(sec, address.FromKey(newKey()), 1, 1))
+
+	for i := 0; i < 1000 && n.Height() < 2; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if act := n.Height(); act != 2 {
+		t.Fatalf("Expected: 2, got: %d", act)
+	}
+}
+
+func TestNodeStartStop(t *testing.T) {
+	n, _ := devnet.New(devnet.Config{
+		Allocations: []genesis.Allocation{{Address: address.FromKey(newKey()), Value: 100}},
+		Interval:    time.Microsecond,
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				n.Start()
+				n.Stop()
+			}
+		}()
+	}
+
+	wg.Wait()
+	n.Stop()
+}
+
+func mustBlock(t *testing.T, n *devnet.Node, h int) *block.Block {
+	b, err := n.Block(h)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	return b
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package envelope moves unsigned transactions to an offline signer and
+// signed transactions back.
+//
+// An Envelope carries the unsigned part of a transaction, the address that
+// is expected to sign it and a human-readable summary, protected by a
+// checksum. The summary is derived from the transaction, so the offline
+// side can show it and trust it once Verify succeeds.
+package envelope
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+// Version is the envelope format version.
+const Version = 1
+
+// unsignedLength is the length of the signed part of a transaction.
+const unsignedLength = 85
+
+var (
+	ErrInvalidEnvelope  = errors.New("envelope: invalid envelope")
+	ErrInvalidChecksum  = errors.New("envelope: checksum mismatch")
+	ErrInvalidSummary   = errors.New("envelope: summary does not match transaction")
+	ErrSignerMismatch   = errors.New("envelope: key does not match signer")
+	ErrContentMismatch  = errors.New("envelope: transaction does not match envelope")
+	ErrInvalidSignature = errors.New("envelope: invalid signature")
+)
+
+type Envelope struct {
+	Version     int    `json:"version"`
+	Transaction string `json:"transaction"`
+	Signer      string `json:"signer"`
+	Summary     string `json:"summary"`
+	Checksum    string `json:"checksum"`
+}
+
+// New wraps the unsigned part of t, the sender of t is the expected signer.
+func New(t *transaction.Transaction) *Envelope {
+	e := &Envelope{
+		Version:     Version,
+		Transaction: hex.EncodeToString(t.Bytes[0:unsignedLength]),
+		Signer:      t.Sender().ToBech32(),
+		Summary:     Summarize(t),
+	}
+	e.Checksum = e.checksum()
+
+	return e
+}
+
+// Parse decodes and verifies a JSON envelope.
+func Parse(data []byte) (*Envelope, error) {
+	e := &Envelope{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(e); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+
+	if err := e.Verify(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Marshal encodes e as JSON.
+func (e *Envelope) Marshal() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
+
+// Verify checks the checksum, that the signer is the sender and that the
+// summary describes the transaction.
+func (e *Envelope) Verify() error {
+	if e.Version != Version {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, e.Version)
+	}
+
+	if e.Checksum != e.checksum() {
+		return ErrInvalidChecksum
+	}
+
+	t, err := e.unsigned()
+	if err != nil {
+		return err
+	}
+
+	// An invalid sender encodes to an empty string, so an empty signer
+	// must not be taken as a match.
+	if e.Signer == "" {
+		return fmt.Errorf("%w: missing signer", ErrInvalidEnvelope)
+	}
+
+	if t.Sender().ToBech32() != e.Signer {
+		return fmt.Errorf("%w: signer is not the sender", ErrInvalidEnvelope)
+	}
+
+	if Summarize(t) != e.Summary {
+		return ErrInvalidSummary
+	}
+
+	return nil
+}
+
+// Unsigned returns the transaction with an empty signature.
+func (e *Envelope) Unsigned() (*transaction.Transaction, error) {
+	if err := e.Verify(); err != nil {
+		return nil, err
+	}
+
+	return e.unsigned()
+}
+
+// Sign verifies e and signs its transaction with k, which must belong to
+// the expected signer.
+func (e *Envelope) Sign(k *key.SecretKey) (*transaction.Transaction, error) {
+	t, err := e.Unsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(k.PublicKey().ToBytes(), t.Sender().PublicKey().ToBytes()) {
+		return nil, ErrSignerMismatch
+	}
+
+	return t.Sign(*k), nil
+}
+
+// VerifySigned checks that t is the transaction of e with a valid signature
+// of the expected signer.
+func (e *Envelope) VerifySigned(t *transaction.Transaction) error {
+	u, err := e.Unsigned()
+	if err != nil {
+		return err
+	}
+
+	if len(t.Bytes) != transaction.Length || !bytes.Equal(t.Bytes[0:unsignedLength], u.Bytes[0:unsignedLength]) {
+		return ErrContentMismatch
+	}
+
+	if !t.Sender().PublicKey().VerifySignature(t.Signature(), t.Bytes[0:unsignedLength]) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (e *Envelope) unsigned() (*transaction.Transaction, error) {
+	b, err := hex.DecodeString(e.Transaction)
+	if err != nil || len(b) != unsignedLength {
+		return nil, fmt.Errorf("%w: transaction", ErrInvalidEnvelope)
+	}
+
+	t := transaction.NewTransaction()
+	copy(t.Bytes, b)
+
+	return t, nil
+}
+
+func (e *Envelope) checksum() string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d\n%s\n%s\n%s", e.Version, e.Transaction, e.Signer, e.Summary)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Summarize describes t in a single line.
+func Summarize(t *transaction.Transaction) string {
+	rcp := t.Recipient()
+
+	switch t.Version() {
+	case transaction.Genesis:
+		return fmt.Sprintf("Genesis: credit %s UMI to %s", t.Amount(), bech32(rcp))
+	case transaction.Basic:
+		return fmt.Sprintf("Send %s UMI from %s to %s, nonce %d", t.Amount(), bech32(t.Sender()), bech32(rcp), t.Nonce())
+	case transaction.CreateSmartContract, transaction.UpdateSmartContract:
+		verb := "Create"
+		if t.Version() == transaction.UpdateSmartContract {
+			verb = "Update"
+		}
+
+		return fmt.Sprintf("%s structure %q named %q: profit %s, fee %s, nonce %d",
+			verb, t.Prefix(), t.Name(), percent(t.ProfitPercent()), percent(t.FeePercent()), t.Nonce())
+	case transaction.UpdateProfitAddress:
+		return fmt.Sprintf("Set profit address of structure %q to %s, nonce %d", rcp.Prefix(), bech32(rcp), t.Nonce())
+	case transaction.UpdateFeeAddress:
+		return fmt.Sprintf("Set fee address of structure %q to %s, nonce %d", rcp.Prefix(), bech32(rcp), t.Nonce())
+	case transaction.CreateTransitAddress:
+		return fmt.Sprintf("Add transit address %s to structure %q, nonce %d", bech32(rcp), rcp.Prefix(), t.Nonce())
+	case transaction.DeleteTransitAddress:
+		return fmt.Sprintf("Remove transit address %s from structure %q, nonce %d", bech32(rcp), rcp.Prefix(), t.Nonce())
+	default:
+		return fmt.Sprintf("Unknown transaction version %d", t.Version())
+	}
+}
+
+func bech32(a *address.Address) string {
+	if a.Verify() != nil {
+		return "<invalid address>"
+	}
+
+	return a.ToBech32()
+}
+
+func percent(p uint16) string {
+	return fmt.Sprintf("%d.%02d%%", p/100, p%100)
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package envelope_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/envelope"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func TestRoundTrip(t *testing.T) {
+	sec := newKey()
+	rcp := address.FromKey(newKey())
+	tx := transaction.NewTransaction().SetSender(address.FromKey(sec)).SetRecipient(rcp).SetValue(1230).SetNonce(7)
+
+	// online
+	data, err := envelope.New(tx).Marshal()
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	// offline
+	env, err := envelope.Parse(data)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	exp := "Send 12.30 UMI from " + address.FromKey(sec).ToBech32() + " to " + rcp.ToBech32() + ", nonce 7"
+	if env.Summary != exp {
+		t.Fatalf("Expected: %s, got: %s", exp, env.Summary)
+	}
+
+	if _, err := env.Sign(newKey()); !errors.Is(err, envelope.ErrSignerMismatch) {
+		t.Fatalf("Expected: %v, got: %v", envelope.ErrSignerMismatch, err)
+	}
+
+	signed, err := env.Sign(sec)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	// online
+	if err := envelope.New(tx).VerifySigned(signed); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := signed.Verify(); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	other := transaction.FromBytes(signed.Bytes).SetValue(1231).Sign(*sec)
+	if err := envelope.New(tx).VerifySigned(other); !errors.Is(err, envelope.ErrContentMismatch) {
+		t.Fatalf("Expected: %v, got: %v", envelope.ErrContentMismatch, err)
+	}
+
+	forged := transaction.FromBytes(signed.Bytes).SetSignature(make([]byte, 64))
+	if err := envelope.New(tx).VerifySigned(forged); !errors.Is(err, envelope.ErrInvalidSignature) {
+		t.Fatalf("Expected: %v, got: %v", envelope.ErrInvalidSignature, err)
+	}
+}
+
+func TestTampering(t *testing.T) {
+	sec := newKey()
+	tx := transaction.NewTransaction().SetSender(address.FromKey(sec)).SetRecipient(address.FromKey(newKey())).SetValue(100)
+
+	cases := []struct {
+		desc string
+		mod  func(e *envelope.Envelope)
+		err  error
+	}{
+		{"summary", func(e *envelope.Envelope) { e.Summary = "Send 0.01 UMI" }, envelope.ErrInvalidChecksum},
+		{"signer", func(e *envelope.Envelope) { e.Signer = address.FromKey(newKey()).ToBech32() }, envelope.ErrInvalidChecksum},
+		{"transaction", func(e *envelope.Envelope) {
+			e.Transaction = strings.Repeat("0", len(e.Transaction))
+		}, envelope.ErrInvalidChecksum},
+		{"resealed summary", func(e *envelope.Envelope) {
+			e.Summary = "Send 0.01 UMI"
+			e.Checksum = reseal(e)
+		}, envelope.ErrInvalidSummary},
+		{"resealed signer", func(e *envelope.Envelope) {
+			e.Signer = address.FromKey(newKey()).ToBech32()
+			e.Checksum = reseal(e)
+		}, envelope.ErrInvalidEnvelope},
+		{"short transaction", func(e *envelope.Envelope) {
+			e.Transaction = e.Transaction[2:]
+			e.Checksum = reseal(e)
+		}, envelope.ErrInvalidEnvelope},
+		{"version", func(e *envelope.Envelope) { e.Version = 2 }, envelope.ErrInvalidEnvelope},
+		{"empty signer", func(e *envelope.Envelope) {
+			e.Signer = ""
+			e.Checksum = reseal(e)
+		}, envelope.ErrInvalidEnvelope},
+	}
+
+	for _, tc := range cases {
+		e := envelope.New(tx)
+		tc.mod(e)
+
+		if err := e.Verify(); !errors.Is(err, tc.err) {
+			t.Fatalf("%s: Expected: %v, got: %v", tc.desc, tc.err, err)
+		}
+
+		if _, err := e.Sign(sec); !errors.Is(err, tc.err) {
+			t.Fatalf("%s: Expected: %v, got: %v", tc.desc, tc.err, err)
+		}
+	}
+
+	if _, err := envelope.Parse([]byte(`{"version":1,"extra":true}`)); !errors.Is(err, envelope.ErrInvalidEnvelope) {
+		t.Fatalf("Expected: %v, got: %v", envelope.ErrInvalidEnvelope, err)
+	}
+
+	// A sender with an invalid prefix has no bech32 form.
+	tx.Bytes[1], tx.Bytes[2] = 0xff, 0xff
+
+	if err := envelope.New(tx).Verify(); !errors.Is(err, envelope.ErrInvalidEnvelope) {
+		t.Fatalf("Expected: %v, got: %v", envelope.ErrInvalidEnvelope, err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	own := address.FromKey(newKey())
+	srv := address.FromKey(newKey()).SetPrefix("aaa")
+
+	cases := []struct {
+		tx  *transaction.Transaction
+		exp string
+	}{
+		{
+			transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).SetSender(own).
+				SetPrefix("aaa").SetProfitPercent(150).SetFeePercent(2000).SetNonce(1),
+			`Create structure "aaa" named "": profit 1.50%, fee 20.00%, nonce 1`,
+		},
+		{
+			transaction.NewTransaction().SetVersion(transaction.UpdateFeeAddress).SetSender(own).SetRecipient(srv),
+			`Set fee address of structure "aaa" to ` + srv.ToBech32() + `, nonce 0`,
+		},
+		{
+			transaction.NewTransaction().SetVersion(transaction.DeleteTransitAddress).SetSender(own).SetRecipient(srv),
+			`Remove transit address ` + srv.ToBech32() + ` from structure "aaa", nonce 0`,
+		},
+	}
+
+	for _, tc := range cases {
+		if act := envelope.Summarize(tc.tx); act != tc.exp {
+			t.Fatalf("Expected: %s, got: %s", tc.exp, act)
+		}
+	}
+}
+
+// reseal recomputes the checksum the way an attacker with the format
+// description would.
+func reseal(e *envelope.Envelope) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%d\n%s\n%s\n%s", e.Version, e.Transaction, e.Signer, e.Summary)))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package frost
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/edwards25519"
+	"github.com/umi-top/umi-core/key"
+)
+
+var (
+	ErrInvalidPackage = errors.New("frost: invalid dkg package")
+	ErrInvalidProof   = errors.New("frost: invalid proof of knowledge")
+	ErrDKGState       = errors.New("frost: dkg round out of order")
+)
+
+// Round1Package is broadcast to all participants: the commitment to the
+// secret polynomial and a proof of knowledge of its constant term.
+type Round1Package struct {
+	ID         uint16
+	Commitment [][]byte
+	ProofR     []byte
+	ProofZ     []byte
+}
+
+// Round2Package carries the share of From for To. It is secret and must
+// be sent over a confidential and authenticated channel.
+type Round2Package struct {
+	From, To uint16
+	Share    []byte
+}
+
+// DKG is the state of one participant of a Pedersen distributed key
+// generation with proofs of knowledge, as in the FROST paper.
+type DKG struct {
+	id        uint16
+	threshold int
+	n         int
+	coef      []*edwards25519.Scalar
+	round1    map[uint16]*Round1Package
+}
+
+// NewDKG starts key generation for participant id of n and returns the
+// package to broadcast.
+func NewDKG(id uint16, threshold, n int, rand io.Reader) (*DKG, *Round1Package, error) {
+	if threshold < 2 || threshold > n || n > 0xFFFF {
+		return nil, nil, ErrInvalidParams
+	}
+
+	if id == 0 || int(id) > n {
+		return nil, nil, ErrInvalidIdentifier
+	}
+
+	d := &DKG{id: id, threshold: threshold, n: n, coef: make([]*edwards25519.Scalar, threshold)}
+	pkg := &Round1Package{ID: id, Commitment: make([][]byte, threshold)}
+
+	for i := range d.coef {
+		c, err := randomScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		d.coef[i] = c
+		pkg.Commitment[i] = scalarBaseMult(c).bytes()
+	}
+
+	k, err := randomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := scalarBaseMult(k).bytes()
+	c := proofChallenge(id, pkg.Commitment[0], r)
+
+	pkg.ProofR = r
+	pkg.ProofZ = encodeScalar(scAdd(k, scMul(d.coef[0], c)))
+
+	return d, pkg, nil
+}
+
+func proofChallenge(id uint16, pub, r []byte) *edwards25519.Scalar {
+	return hashToScalar([]byte(contextString+"dkg"), encodeIdentifier(id), pub, r)
+}
+
+// Round2 verifies the round one packages of all other participants and
+// returns the secret shares to send to each of them.
+func (d *DKG) Round2(packages []*Round1Package) ([]*Round2Package, error) {
+	if d.coef == nil || d.round1 != nil {
+		return nil, ErrDKGState
+	}
+
+	r1 := make(map[uint16]*Round1Package, len(packages))
+
+	for _, p := range packages {
+		if p == nil || p.ID == d.id || p.ID == 0 || int(p.ID) > d.n || len(p.Commitment) != d.threshold {
+			return nil, ErrInvalidPackage
+		}
+
+		if _, ok := r1[p.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate package of %d", ErrInvalidPackage, p.ID)
+		}
+
+		if err := verifyRound1(p); err != nil {
+			return nil, err
+		}
+
+		r1[p.ID] = p
+	}
+
+	if len(r1) != d.n-1 {
+		return nil, fmt.Errorf("%w: expected %d packages, got %d", ErrInvalidPackage, d.n-1, len(r1))
+	}
+
+	d.round1 = r1
+	out := make([]*Round2Package, 0, d.n-1)
+
+	for j := 1; j <= d.n; j++ {
+		if uint16(j) == d.id {
+			continue
+		}
+
+		out = append(out, &Round2Package{From: d.id, To: uint16(j), Share: encodeScalar(evaluate(d.coef, uint16(j)))})
+	}
+
+	return out, nil
+}
+
+func verifyRound1(p *Round1Package) error {
+	for _, c := range p.Commitment {
+		if _, err := decodeElement(c); err != nil {
+			return fmt.Errorf("%w: commitment of %d", ErrInvalidPackage, p.ID)
+		}
+	}
+
+	r, err := decodeElement(p.ProofR)
+	if err != nil {
+		return fmt.Errorf("%w: %d", ErrInvalidProof, p.ID)
+	}
+
+	z, err := decodeScalar(p.ProofZ)
+	if err != nil {
+		return fmt.Errorf("%w: %d", ErrInvalidProof, p.ID)
+	}
+
+	pub, _ := decodeElement(p.Commitment[0])
+	c := proofChallenge(p.ID, p.Commitment[0], p.ProofR)
+
+	// g^z == R + A * c
+	if !scalarBaseMult(z).equal(r.add(pub.mul(c))) {
+		return fmt.Errorf("%w: %d", ErrInvalidProof, p.ID)
+	}
+
+	return nil
+}
+
+// Finish verifies the shares received from all other participants and
+// returns the key share of d. The secret polynomial is discarded.
+func (d *DKG) Finish(packages []*Round2Package) (*KeyShare, error) {
+	if d.round1 == nil || d.coef == nil {
+		return nil, ErrDKGState
+	}
+
+	secret := evaluate(d.coef, d.id)
+	seen := make(map[uint16]struct{}, len(packages))
+
+	for _, p := range packages {
+		r1, ok := d.round1[p.From]
+		if !ok || p.To != d.id {
+			return nil, ErrInvalidPackage
+		}
+
+		if _, ok := seen[p.From]; ok {
+			return nil, fmt.Errorf("%w: duplicate share of %d", ErrInvalidPackage, p.From)
+		}
+
+		seen[p.From] = struct{}{}
+
+		s, err := decodeScalar(p.Share)
+		if err != nil || !scalarBaseMult(s).equal(commitmentAt(r1.Commitment, d.id)) {
+			return nil, fmt.Errorf("%w: share of %d", ErrInvalidShare, p.From)
+		}
+
+		secret = scAdd(secret, s)
+	}
+
+	if len(seen) != d.n-1 {
+		return nil, fmt.Errorf("%w: expected %d shares, got %d", ErrInvalidPackage, d.n-1, len(seen))
+	}
+
+	own := make([][]byte, d.threshold)
+	for i, c := range d.coef {
+		own[i] = scalarBaseMult(c).bytes()
+	}
+
+	all := [][][]byte{own}
+	for _, p := range d.round1 {
+		all = append(all, p.Commitment)
+	}
+
+	group := identity()
+	for _, c := range all {
+		a, _ := decodeElement(c[0])
+		group = group.add(a)
+	}
+
+	pkg := PublicKeyPackage{
+		Threshold: d.threshold,
+		GroupKey:  key.NewPublicKey(group.bytes()),
+		Shares:    make(map[uint16][]byte, d.n),
+	}
+
+	for j := 1; j <= d.n; j++ {
+		v := identity()
+		for _, c := range all {
+			v = v.add(commitmentAt(c, uint16(j)))
+		}
+
+		pkg.Shares[uint16(j)] = v.bytes()
+	}
+
+	d.coef = nil
+	s := &KeyShare{ID: d.id, PublicKeyPackage: pkg, secret: secret}
+
+	if err := s.Verify(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// commitmentAt evaluates a committed polynomial at x in the exponent.
+func commitmentAt(commitment [][]byte, x uint16) *point {
+	r := identity()
+	bx := scalarFromUint16(x)
+
+	for i := len(commitment) - 1; i >= 0; i-- {
+		c, _ := decodeElement(commitment[i])
+		r = r.mul(bx).add(c)
+	}
+
+	return r
+}
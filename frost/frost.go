@@ -0,0 +1,252 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package frost implements FROST(Ed25519, SHA-512) threshold signing as
+// specified in RFC 9591.
+//
+// Any Threshold of the holders of a key share can produce a signature that
+// verifies with the group key.PublicKey like a plain ed25519 signature, so
+// it can be used as a Transaction or Block signature. Shares are created by
+// a trusted dealer (Deal, Split) or by a distributed key generation (DKG).
+//
+// The group arithmetic uses filippo.io/edwards25519, operations on secret
+// scalars are constant time.
+package frost
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+
+	"filippo.io/edwards25519"
+	"github.com/umi-top/umi-core/key"
+)
+
+const contextString = "FROST-ED25519-SHA512-v1"
+
+var (
+	ErrInvalidParams     = errors.New("frost: invalid threshold or number of participants")
+	ErrInvalidShare      = errors.New("frost: invalid key share")
+	ErrInvalidIdentifier = errors.New("frost: invalid identifier")
+)
+
+// PublicKeyPackage is the public part of a key generation: the threshold,
+// the group key and the verifying share of every participant.
+type PublicKeyPackage struct {
+	Threshold int
+	GroupKey  *key.PublicKey
+	Shares    map[uint16][]byte
+}
+
+// KeyShare is the secret share of participant ID.
+type KeyShare struct {
+	ID uint16
+	PublicKeyPackage
+
+	secret *edwards25519.Scalar
+}
+
+// Deal generates a random group key and splits it into n shares, any
+// threshold of which can sign.
+func Deal(threshold, n int, rand io.Reader) ([]*KeyShare, error) {
+	s, err := randomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	return deal(s, threshold, n, rand)
+}
+
+// Split shares an existing key, the group key is k.PublicKey().
+// The dealer should destroy k afterwards.
+func Split(k *key.SecretKey, threshold, n int, rand io.Reader) ([]*KeyShare, error) {
+	h := sha512.Sum512(k.ToBytes()[0:32])
+
+	s, err := edwards25519.NewScalar().SetBytesWithClamping(h[0:32])
+	if err != nil {
+		return nil, err
+	}
+
+	return deal(s, threshold, n, rand)
+}
+
+func deal(secret *edwards25519.Scalar, threshold, n int, rand io.Reader) ([]*KeyShare, error) {
+	if threshold < 2 || threshold > n || n > 0xFFFF {
+		return nil, ErrInvalidParams
+	}
+
+	coef := make([]*edwards25519.Scalar, threshold)
+	coef[0] = secret
+
+	for i := 1; i < threshold; i++ {
+		c, err := randomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+
+		coef[i] = c
+	}
+
+	pkg := PublicKeyPackage{
+		Threshold: threshold,
+		GroupKey:  key.NewPublicKey(scalarBaseMult(secret).bytes()),
+		Shares:    make(map[uint16][]byte, n),
+	}
+
+	shares := make([]*KeyShare, n)
+
+	for i := range shares {
+		id := uint16(i + 1)
+		s := evaluate(coef, id)
+		pkg.Shares[id] = scalarBaseMult(s).bytes()
+		shares[i] = &KeyShare{ID: id, secret: s}
+	}
+
+	// Every participant gets its own copy of the package.
+	for _, s := range shares {
+		s.PublicKeyPackage = pkg.clone()
+	}
+
+	return shares, nil
+}
+
+func (p *PublicKeyPackage) clone() PublicKeyPackage {
+	c := PublicKeyPackage{
+		Threshold: p.Threshold,
+		GroupKey:  key.NewPublicKey(p.GroupKey.ToBytes()),
+		Shares:    make(map[uint16][]byte, len(p.Shares)),
+	}
+
+	for id, v := range p.Shares {
+		c.Shares[id] = append([]byte(nil), v...)
+	}
+
+	return c
+}
+
+// evaluate returns the polynomial with coefficients coef at x.
+func evaluate(coef []*edwards25519.Scalar, x uint16) *edwards25519.Scalar {
+	r := edwards25519.NewScalar()
+	bx := scalarFromUint16(x)
+
+	for i := len(coef) - 1; i >= 0; i-- {
+		r = scAdd(scMul(r, bx), coef[i])
+	}
+
+	return r
+}
+
+func randomScalar(rand io.Reader) (*edwards25519.Scalar, error) {
+	b := make([]byte, 64)
+
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+
+		s, _ := edwards25519.NewScalar().SetUniformBytes(b)
+		if !isZero(s) {
+			return s, nil
+		}
+	}
+}
+
+// Verify checks that the secret of s matches its verifying share.
+func (s *KeyShare) Verify() error {
+	if s.secret == nil || s.ID == 0 {
+		return ErrInvalidShare
+	}
+
+	v, ok := s.Shares[s.ID]
+	if !ok || string(scalarBaseMult(s.secret).bytes()) != string(v) {
+		return ErrInvalidShare
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes s as id, threshold, secret, group key and the
+// verifying shares ordered by identifier. The result is secret.
+func (s *KeyShare) MarshalBinary() ([]byte, error) {
+	if err := s.Verify(); err != nil {
+		return nil, err
+	}
+
+	ids := s.identifiers()
+	b := make([]byte, 6, 6+64+len(ids)*34)
+	binary.BigEndian.PutUint16(b[0:2], s.ID)
+	binary.BigEndian.PutUint16(b[2:4], uint16(s.Threshold))
+	binary.BigEndian.PutUint16(b[4:6], uint16(len(ids)))
+	b = append(b, encodeScalar(s.secret)...)
+	b = append(b, s.GroupKey.ToBytes()...)
+
+	for _, id := range ids {
+		b = append(b, byte(id>>8), byte(id))
+		b = append(b, s.Shares[id]...)
+	}
+
+	return b, nil
+}
+
+func (s *KeyShare) UnmarshalBinary(b []byte) error {
+	if len(b) < 6 || len(b) != 6+64+int(binary.BigEndian.Uint16(b[4:6]))*34 {
+		return ErrInvalidShare
+	}
+
+	secret, err := decodeScalar(b[6:38])
+	if err != nil {
+		return ErrInvalidShare
+	}
+
+	q := KeyShare{
+		ID:     binary.BigEndian.Uint16(b[0:2]),
+		secret: secret,
+		PublicKeyPackage: PublicKeyPackage{
+			Threshold: int(binary.BigEndian.Uint16(b[2:4])),
+			GroupKey:  key.NewPublicKey(b[38:70]),
+			Shares:    make(map[uint16][]byte),
+		},
+	}
+
+	for off := 70; off < len(b); off += 34 {
+		q.Shares[binary.BigEndian.Uint16(b[off:off+2])] = append([]byte(nil), b[off+2:off+34]...)
+	}
+
+	if err := q.Verify(); err != nil {
+		return err
+	}
+
+	*s = q
+
+	return nil
+}
+
+func (p *PublicKeyPackage) identifiers() []uint16 {
+	ids := make([]uint16, 0, len(p.Shares))
+	for id := range p.Shares {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package frost_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/frost"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+// sign runs both rounds with the given signers.
+func sign(t *testing.T, signers []*frost.KeyShare, msg []byte) []byte {
+	nonces := make([]*frost.Nonces, len(signers))
+	commitments := make([]frost.Commitment, len(signers))
+
+	for i, s := range signers {
+		n, err := frost.Commit(s, rand.Reader)
+		if err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+
+		nonces[i], commitments[i] = n, n.Commitment
+	}
+
+	shares := make([]frost.SignatureShare, len(signers))
+
+	for i, s := range signers {
+		sh, err := frost.Sign(s, nonces[i], msg, commitments)
+		if err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+
+		shares[i] = sh
+	}
+
+	sig, err := frost.Aggregate(&signers[0].PublicKeyPackage, msg, commitments, shares)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	return sig
+}
+
+func TestSplit(t *testing.T) {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	k := key.NewSecretKey(sec)
+
+	shares, err := frost.Split(k, 2, 3, rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if !bytes.Equal(shares[0].GroupKey.ToBytes(), k.PublicKey().ToBytes()) {
+		t.Fatalf("Expected: group key %x, got: %x", k.PublicKey().ToBytes(), shares[0].GroupKey.ToBytes())
+	}
+
+	tx := transaction.NewTransaction().SetSender(address.FromKey(k)).
+		SetRecipient(address.FromKey(shares[0].GroupKey).SetPrefix("aaa")).SetValue(42)
+
+	for _, pair := range [][]*frost.KeyShare{{shares[0], shares[1]}, {shares[2], shares[0]}, shares} {
+		tx.SetSignature(sign(t, pair, tx.Bytes[0:85]))
+
+		if err := tx.Verify(); err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+	}
+}
+
+func TestDeal(t *testing.T) {
+	shares, err := frost.Deal(3, 5, rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	b := block.NewBlock()
+	copy(b.Bytes[71:103], shares[0].GroupKey.ToBytes())
+	copy(b.Bytes[103:167], sign(t, []*frost.KeyShare{shares[4], shares[1], shares[3]}, b.Bytes[0:103]))
+
+	if !b.Verify() {
+		t.Fatalf("Expected: block signature to verify")
+	}
+
+	// Changing one participant's package does not affect the others.
+	shares[0].Shares[2][0] ^= 1
+	delete(shares[0].Shares, 3)
+
+	if err := shares[1].Verify(); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if _, ok := shares[2].Shares[3]; !ok {
+		t.Fatalf("Expected: share 3 to be kept")
+	}
+
+	for _, c := range []struct{ m, n int }{{1, 3}, {4, 3}, {0, 0}} {
+		if _, err := frost.Deal(c.m, c.n, rand.Reader); !errors.Is(err, frost.ErrInvalidParams) {
+			t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidParams, err)
+		}
+	}
+}
+
+func TestDKG(t *testing.T) {
+	const threshold, n = 2, 3
+
+	dkgs := make([]*frost.DKG, n)
+	r1 := make([]*frost.Round1Package, n)
+
+	for i := range dkgs {
+		d, p, err := frost.NewDKG(uint16(i+1), threshold, n, rand.Reader)
+		if err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+
+		dkgs[i], r1[i] = d, p
+	}
+
+	inbox := make(map[uint16][]*frost.Round2Package)
+
+	for i, d := range dkgs {
+		others := append(append([]*frost.Round1Package{}, r1[:i]...), r1[i+1:]...)
+
+		out, err := d.Round2(others)
+		if err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+
+		for _, p := range out {
+			inbox[p.To] = append(inbox[p.To], p)
+		}
+	}
+
+	shares := make([]*frost.KeyShare, n)
+
+	for i, d := range dkgs {
+		s, err := d.Finish(inbox[uint16(i+1)])
+		if err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+
+		shares[i] = s
+	}
+
+	for _, s := range shares[1:] {
+		if !bytes.Equal(s.GroupKey.ToBytes(), shares[0].GroupKey.ToBytes()) {
+			t.Fatalf("Expected: all participants to agree on the group key")
+		}
+	}
+
+	msg := []byte("dkg")
+	if !shares[0].GroupKey.VerifySignature(sign(t, shares[1:], msg), msg) {
+		t.Fatalf("Expected: signature to verify")
+	}
+}
+
+func TestDKGErrors(t *testing.T) {
+	d1, p1, _ := frost.NewDKG(1, 2, 2, rand.Reader)
+	d2, p2, _ := frost.NewDKG(2, 2, 2, rand.Reader)
+
+	bad := *p2
+	bad.ProofZ = p1.ProofZ
+
+	if _, err := d1.Round2([]*frost.Round1Package{&bad}); !errors.Is(err, frost.ErrInvalidProof) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidProof, err)
+	}
+
+	if _, err := d1.Finish(nil); !errors.Is(err, frost.ErrDKGState) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrDKGState, err)
+	}
+
+	out1, _ := d1.Round2([]*frost.Round1Package{p2})
+	_, _ = d2.Round2([]*frost.Round1Package{p1})
+
+	forged := *out1[0]
+	forged.Share = make([]byte, 32)
+	forged.Share[0] = 1
+
+	if _, err := d2.Finish([]*frost.Round2Package{&forged}); !errors.Is(err, frost.ErrInvalidShare) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidShare, err)
+	}
+
+	if _, err := d2.Finish(out1); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	shares, _ := frost.Deal(2, 3, rand.Reader)
+	msg := []byte("msg")
+
+	n0, _ := frost.Commit(shares[0], rand.Reader)
+	n1, _ := frost.Commit(shares[1], rand.Reader)
+	cs := []frost.Commitment{n0.Commitment, n1.Commitment}
+
+	if _, err := frost.Sign(shares[0], n0, msg, cs[:1]); !errors.Is(err, frost.ErrTooFewSigners) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrTooFewSigners, err)
+	}
+
+	s0, _ := frost.Sign(shares[0], n0, msg, cs)
+	s1, _ := frost.Sign(shares[1], n1, msg, cs)
+
+	if _, err := frost.Sign(shares[0], n0, msg, cs); !errors.Is(err, frost.ErrNonceReused) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrNonceReused, err)
+	}
+
+	n2, _ := frost.Commit(shares[0], rand.Reader)
+	cs2 := []frost.Commitment{n2.Commitment, n1.Commitment}
+
+	if _, err := frost.Aggregate(&shares[0].PublicKeyPackage, msg, cs, []frost.SignatureShare{s0, s0}); !errors.Is(err, frost.ErrInvalidSignatureShare) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidSignatureShare, err)
+	}
+
+	// A share for another message or commitment list is detected.
+	if _, err := frost.Aggregate(&shares[0].PublicKeyPackage, []byte("other"), cs, []frost.SignatureShare{s0, s1}); !errors.Is(err, frost.ErrInvalidSignatureShare) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidSignatureShare, err)
+	}
+
+	if _, err := frost.Aggregate(&shares[0].PublicKeyPackage, msg, cs2, []frost.SignatureShare{s0, s1}); !errors.Is(err, frost.ErrInvalidSignatureShare) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidSignatureShare, err)
+	}
+
+	altered := append([]frost.Commitment{}, cs...)
+	altered[0].Hiding = n1.Commitment.Hiding
+
+	if _, err := frost.Sign(shares[0], n2, msg, altered); !errors.Is(err, frost.ErrInvalidCommitment) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidCommitment, err)
+	}
+
+	stranger := []frost.Commitment{n0.Commitment, {ID: 9, Hiding: n0.Commitment.Hiding, Binding: n0.Commitment.Binding}}
+	if _, err := frost.Sign(shares[0], n2, msg, stranger); !errors.Is(err, frost.ErrInvalidIdentifier) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidIdentifier, err)
+	}
+}
+
+func TestKeyShareBinary(t *testing.T) {
+	shares, _ := frost.Deal(2, 3, rand.Reader)
+
+	b, err := shares[1].MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	s := &frost.KeyShare{}
+	if err := s.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	msg := []byte("binary")
+	if !s.GroupKey.VerifySignature(sign(t, []*frost.KeyShare{s, shares[2]}, msg), msg) {
+		t.Fatalf("Expected: signature to verify")
+	}
+
+	b[10] ^= 1
+	if err := s.UnmarshalBinary(b); !errors.Is(err, frost.ErrInvalidShare) {
+		t.Fatalf("Expected: %v, got: %v", frost.ErrInvalidShare, err)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package frost
+
+import (
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+)
+
+// The edwards25519 group. Scalar multiplication and scalar arithmetic are
+// constant time, only the handling of public encodings branches.
+
+var errInvalidEncoding = errors.New("frost: invalid encoding")
+
+// minusOne is L - 1, [L-1]P + P is the identity for elements of the prime
+// order subgroup.
+var minusOne = edwards25519.NewScalar().Negate(scalarFromUint16(1))
+
+type point struct {
+	v *edwards25519.Point
+}
+
+func identity() *point {
+	return &point{v: edwards25519.NewIdentityPoint()}
+}
+
+func (p *point) add(q *point) *point {
+	return &point{v: new(edwards25519.Point).Add(p.v, q.v)}
+}
+
+func (p *point) mul(k *edwards25519.Scalar) *point {
+	return &point{v: new(edwards25519.Point).ScalarMult(k, p.v)}
+}
+
+func (p *point) equal(q *point) bool {
+	return p.v.Equal(q.v) == 1
+}
+
+func (p *point) isIdentity() bool {
+	return p.equal(identity())
+}
+
+func (p *point) bytes() []byte {
+	return p.v.Bytes()
+}
+
+// decodePoint decodes a canonical compressed point, RFC 8032 section 5.1.3.
+func decodePoint(b []byte) (*point, error) {
+	v, err := new(edwards25519.Point).SetBytes(b)
+	if err != nil || string(v.Bytes()) != string(b) {
+		return nil, errInvalidEncoding
+	}
+
+	return &point{v: v}, nil
+}
+
+// decodeElement is decodePoint restricted to non-identity elements of the
+// prime order subgroup.
+func decodeElement(b []byte) (*point, error) {
+	p, err := decodePoint(b)
+	if err != nil || p.isIdentity() || !p.mul(minusOne).add(p).isIdentity() {
+		return nil, errInvalidEncoding
+	}
+
+	return p, nil
+}
+
+func scalarBaseMult(k *edwards25519.Scalar) *point {
+	return &point{v: new(edwards25519.Point).ScalarBaseMult(k)}
+}
+
+func encodeScalar(k *edwards25519.Scalar) []byte {
+	return k.Bytes()
+}
+
+func decodeScalar(b []byte) (*edwards25519.Scalar, error) {
+	k, err := edwards25519.NewScalar().SetCanonicalBytes(b)
+	if err != nil {
+		return nil, errInvalidEncoding
+	}
+
+	return k, nil
+}
+
+func scalarFromUint16(x uint16) *edwards25519.Scalar {
+	b := make([]byte, 32)
+	b[0], b[1] = byte(x), byte(x>>8)
+
+	k, _ := edwards25519.NewScalar().SetCanonicalBytes(b)
+
+	return k
+}
+
+func isZero(k *edwards25519.Scalar) bool {
+	return k.Equal(edwards25519.NewScalar()) == 1
+}
+
+// hashToScalar reduces SHA-512 of the concatenated parts modulo L.
+func hashToScalar(parts ...[]byte) *edwards25519.Scalar {
+	h := sha512.New()
+
+	for _, p := range parts {
+		_, _ = h.Write(p)
+	}
+
+	k, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+
+	return k
+}
+
+func scMul(a, b *edwards25519.Scalar) *edwards25519.Scalar {
+	return edwards25519.NewScalar().Multiply(a, b)
+}
+
+func scAdd(a, b *edwards25519.Scalar) *edwards25519.Scalar {
+	return edwards25519.NewScalar().Add(a, b)
+}
+
+func scSub(a, b *edwards25519.Scalar) *edwards25519.Scalar {
+	return edwards25519.NewScalar().Subtract(a, b)
+}
+
+func scInv(a *edwards25519.Scalar) *edwards25519.Scalar {
+	return edwards25519.NewScalar().Invert(a)
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package frost
+
+import (
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"filippo.io/edwards25519"
+)
+
+var (
+	ErrInvalidCommitment     = errors.New("frost: invalid commitment")
+	ErrTooFewSigners         = errors.New("frost: not enough signers")
+	ErrNonceReused           = errors.New("frost: nonces already used")
+	ErrInvalidSignatureShare = errors.New("frost: invalid signature share")
+)
+
+// Commitment is the public round one output of a signer.
+type Commitment struct {
+	ID      uint16
+	Hiding  []byte
+	Binding []byte
+}
+
+// Nonces is the secret round one output of a signer. It must be used for
+// a single signature only.
+type Nonces struct {
+	Commitment Commitment
+
+	hiding, binding *edwards25519.Scalar
+}
+
+// SignatureShare is the round two output of a signer.
+type SignatureShare struct {
+	ID uint16
+	Z  []byte
+}
+
+// Commit runs round one: it draws fresh nonces for s and returns them with
+// their public commitment, which is sent to the coordinator.
+func Commit(s *KeyShare, rand io.Reader) (*Nonces, error) {
+	if err := s.Verify(); err != nil {
+		return nil, err
+	}
+
+	h, err := nonce(s.secret, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := nonce(s.secret, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Nonces{
+		Commitment: Commitment{ID: s.ID, Hiding: scalarBaseMult(h).bytes(), Binding: scalarBaseMult(b).bytes()},
+		hiding:     h,
+		binding:    b,
+	}, nil
+}
+
+func nonce(secret *edwards25519.Scalar, rand io.Reader) (*edwards25519.Scalar, error) {
+	r := make([]byte, 32)
+	if _, err := io.ReadFull(rand, r); err != nil {
+		return nil, err
+	}
+
+	return h3(r, encodeScalar(secret)), nil
+}
+
+// Sign runs round two: it signs msg with s and the nonces of round one,
+// commitments are those of every participating signer.
+func Sign(s *KeyShare, n *Nonces, msg []byte, commitments []Commitment) (SignatureShare, error) {
+	if n.hiding == nil {
+		return SignatureShare{}, ErrNonceReused
+	}
+
+	if err := s.Verify(); err != nil {
+		return SignatureShare{}, err
+	}
+
+	sc, err := newSession(&s.PublicKeyPackage, msg, commitments)
+	if err != nil {
+		return SignatureShare{}, err
+	}
+
+	c, ok := sc.commitments[s.ID]
+	if !ok || string(c.Hiding) != string(n.Commitment.Hiding) || string(c.Binding) != string(n.Commitment.Binding) {
+		return SignatureShare{}, fmt.Errorf("%w: own commitment missing or altered", ErrInvalidCommitment)
+	}
+
+	// z = d + e * rho + lambda * s * c
+	z := scAdd(n.hiding, scMul(n.binding, sc.rho[s.ID]))
+	z = scAdd(z, scMul(scMul(sc.lambda(s.ID), s.secret), sc.challenge))
+
+	n.hiding, n.binding = nil, nil
+
+	return SignatureShare{ID: s.ID, Z: encodeScalar(z)}, nil
+}
+
+// Aggregate verifies the signature shares and combines them into an
+// ed25519 signature of msg by the group key.
+func Aggregate(p *PublicKeyPackage, msg []byte, commitments []Commitment, shares []SignatureShare) ([]byte, error) {
+	sc, err := newSession(p, msg, commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shares) != len(sc.ids) {
+		return nil, fmt.Errorf("%w: expected %d shares, got %d", ErrInvalidSignatureShare, len(sc.ids), len(shares))
+	}
+
+	z := edwards25519.NewScalar()
+	seen := make(map[uint16]struct{}, len(shares))
+
+	for _, sh := range shares {
+		if _, ok := seen[sh.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate share of %d", ErrInvalidSignatureShare, sh.ID)
+		}
+
+		seen[sh.ID] = struct{}{}
+
+		zi, err := sc.verifyShare(sh)
+		if err != nil {
+			return nil, err
+		}
+
+		z = scAdd(z, zi)
+	}
+
+	return append(sc.r.bytes(), encodeScalar(z)...), nil
+}
+
+// session holds the values all signers derive from the commitment list.
+type session struct {
+	ids         []uint16
+	commitments map[uint16]Commitment
+	hiding      map[uint16]*point
+	binding     map[uint16]*point
+	rho         map[uint16]*edwards25519.Scalar
+	r           *point
+	challenge   *edwards25519.Scalar
+	pkg         *PublicKeyPackage
+}
+
+func newSession(p *PublicKeyPackage, msg []byte, commitments []Commitment) (*session, error) {
+	if len(commitments) < p.Threshold {
+		return nil, ErrTooFewSigners
+	}
+
+	sc := &session{
+		commitments: make(map[uint16]Commitment, len(commitments)),
+		hiding:      make(map[uint16]*point, len(commitments)),
+		binding:     make(map[uint16]*point, len(commitments)),
+		rho:         make(map[uint16]*edwards25519.Scalar, len(commitments)),
+		pkg:         p,
+	}
+
+	for _, c := range commitments {
+		if _, ok := p.Shares[c.ID]; !ok {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidIdentifier, c.ID)
+		}
+
+		if _, ok := sc.commitments[c.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate commitment of %d", ErrInvalidCommitment, c.ID)
+		}
+
+		h, err := decodeElement(c.Hiding)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidCommitment, c.ID)
+		}
+
+		b, err := decodeElement(c.Binding)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidCommitment, c.ID)
+		}
+
+		sc.ids = append(sc.ids, c.ID)
+		sc.commitments[c.ID] = c
+		sc.hiding[c.ID], sc.binding[c.ID] = h, b
+	}
+
+	sort.Slice(sc.ids, func(i, j int) bool { return sc.ids[i] < sc.ids[j] })
+
+	// binding factors, RFC 9591 section 4.4
+	var list []byte
+
+	for _, id := range sc.ids {
+		list = append(list, encodeIdentifier(id)...)
+		list = append(list, sc.commitments[id].Hiding...)
+		list = append(list, sc.commitments[id].Binding...)
+	}
+
+	pk := p.GroupKey.ToBytes()
+	prefix := append(append(append([]byte{}, pk...), h4(msg)...), h5(list)...)
+
+	for _, id := range sc.ids {
+		sc.rho[id] = h1(prefix, encodeIdentifier(id))
+	}
+
+	// group commitment, section 4.5
+	sc.r = identity()
+
+	for _, id := range sc.ids {
+		sc.r = sc.r.add(sc.hiding[id]).add(sc.binding[id].mul(sc.rho[id]))
+	}
+
+	// challenge, section 4.6
+	sc.challenge = hashToScalar(sc.r.bytes(), pk, msg)
+
+	return sc, nil
+}
+
+// lambda is the Lagrange coefficient of id at zero over the signers.
+func (sc *session) lambda(id uint16) *edwards25519.Scalar {
+	num, den := scalarFromUint16(1), scalarFromUint16(1)
+	xi := scalarFromUint16(id)
+
+	for _, j := range sc.ids {
+		if j == id {
+			continue
+		}
+
+		xj := scalarFromUint16(j)
+		num = scMul(num, xj)
+		den = scMul(den, scSub(xj, xi))
+	}
+
+	return scMul(num, scInv(den))
+}
+
+func (sc *session) verifyShare(sh SignatureShare) (*edwards25519.Scalar, error) {
+	if _, ok := sc.commitments[sh.ID]; !ok {
+		return nil, fmt.Errorf("%w: no commitment of %d", ErrInvalidSignatureShare, sh.ID)
+	}
+
+	z, err := decodeScalar(sh.Z)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSignatureShare, sh.ID)
+	}
+
+	pub, err := decodeElement(sc.pkg.Shares[sh.ID])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidShare, sh.ID)
+	}
+
+	// g^z == D + E * rho + PK * (c * lambda)
+	l := scalarBaseMult(z)
+	r := sc.hiding[sh.ID].add(sc.binding[sh.ID].mul(sc.rho[sh.ID])).
+		add(pub.mul(scMul(sc.challenge, sc.lambda(sh.ID))))
+
+	if !l.equal(r) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSignatureShare, sh.ID)
+	}
+
+	return z, nil
+}
+
+func encodeIdentifier(id uint16) []byte {
+	return encodeScalar(scalarFromUint16(id))
+}
+
+func h1(parts ...[]byte) *edwards25519.Scalar {
+	return hashToScalar(append([][]byte{[]byte(contextString + "rho")}, parts...)...)
+}
+
+func h3(parts ...[]byte) *edwards25519.Scalar {
+	return hashToScalar(append([][]byte{[]byte(contextString + "nonce")}, parts...)...)
+}
+
+func h4(msg []byte) []byte {
+	h := sha512.Sum512(append([]byte(contextString+"msg"), msg...))
+	return h[:]
+}
+
+func h5(msg []byte) []byte {
+	h := sha512.Sum512(append([]byte(contextString+"com"), msg...))
+	return h[:]
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package genesis builds and verifies the first block of a network.
+//
+// A genesis block has a zero previous block hash and holds only Genesis
+// transactions. Every transaction is sent from the genesis address of the
+// block signing key, carries its position in the block as nonce and
+// allocates a positive value to a non-genesis address.
+package genesis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/network"
+	"github.com/umi-top/umi-core/transaction"
+	"github.com/umi-top/umi-core/util"
+)
+
+var (
+	ErrNoKey              = errors.New("genesis: signing key is required")
+	ErrNoAllocations      = errors.New("genesis: at least one allocation is required")
+	ErrTooManyAllocations = errors.New("genesis: too many allocations")
+	ErrInvalidAllocation  = errors.New("genesis: invalid allocation")
+	ErrInvalidBlock       = errors.New("genesis: invalid block")
+	ErrInvalidTransaction = errors.New("genesis: invalid transaction")
+)
+
+// Allocation credits Value to Address in the genesis block.
+type Allocation struct {
+	Address *address.Address
+	Value   amount.Amount
+}
+
+// Config describes a genesis block. A nil Params means network.Mainnet().
+type Config struct {
+	Timestamp   uint32
+	Allocations []Allocation
+	Key         *key.SecretKey
+	Params      *network.Params
+}
+
+// NewBlock builds and signs the genesis block described by c.
+func NewBlock(c Config) (*block.Block, error) {
+	if c.Key == nil {
+		return nil, ErrNoKey
+	}
+
+	if len(c.Allocations) == 0 {
+		return nil, ErrNoAllocations
+	}
+
+	if len(c.Allocations) > math.MaxUint16 {
+		return nil, ErrTooManyAllocations
+	}
+
+	p := c.Params
+	if p == nil {
+		p = network.Mainnet()
+	}
+
+	snd := Address(c.Key)
+	b := block.NewBlock().SetVersion(p.BlockVersion)
+	b.SetTimestamp(c.Timestamp)
+
+	for i, a := range c.Allocations {
+		if err := checkAllocation(a.Address, a.Value, p.MaxValue); err != nil {
+			return nil, fmt.Errorf("%w: allocation %d", err, i)
+		}
+
+		t := transaction.NewTransaction().
+			SetVersion(transaction.Genesis).
+			SetSender(snd).
+			SetRecipient(a.Address).
+			SetAmount(a.Value).
+			SetNonce(uint64(i)).
+			Sign(*c.Key)
+
+		b.AppendTransaction(t)
+	}
+
+	b.SetMerkleRootHash(b.CalculateMerkleRoot())
+	b.Sign(c.Key)
+
+	return b, nil
+}
+
+// Address returns the genesis address of k.
+func Address(k key.Key) *address.Address {
+	return address.FromKey(k).SetPrefix(util.GenesisPrefix)
+}
+
+// Verify reports whether b is a valid genesis block.
+func Verify(b *block.Block) error {
+	if len(b.Bytes) < block.HeaderLength {
+		return fmt.Errorf("%w: too short", ErrInvalidBlock)
+	}
+
+	n := b.TxCount()
+
+	if n == 0 || len(b.Bytes) != block.HeaderLength+int(n)*transaction.Length {
+		return fmt.Errorf("%w: transaction count", ErrInvalidBlock)
+	}
+
+	if !bytes.Equal(b.PreviousBlockHash(), make([]byte, 32)) {
+		return fmt.Errorf("%w: previous block hash is not zero", ErrInvalidBlock)
+	}
+
+	if !bytes.Equal(b.MerkleRootHash(), b.CalculateMerkleRoot()) {
+		return fmt.Errorf("%w: merkle root hash", ErrInvalidBlock)
+	}
+
+	if !b.Verify() {
+		return fmt.Errorf("%w: signature", ErrInvalidBlock)
+	}
+
+	pub := b.PublicKey().ToBytes()
+
+	for i := uint16(0); i < n; i++ {
+		if err := verifyTransaction(b.Transaction(i), pub, i); err != nil {
+			return fmt.Errorf("%w: transaction %d", err, i)
+		}
+	}
+
+	return nil
+}
+
+// VerifyWithParams is like Verify but also checks b against p, including
+// its pinned genesis hash.
+func VerifyWithParams(b *block.Block, p *network.Params) error {
+	if err := Verify(b); err != nil {
+		return err
+	}
+
+	for i := uint16(0); i < b.TxCount(); i++ {
+		if b.Transaction(i).Amount() > p.MaxValue {
+			return fmt.Errorf("%w: transaction %d", ErrInvalidTransaction, i)
+		}
+	}
+
+	if err := b.VerifyWithParams(p); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBlock, err)
+	}
+
+	return nil
+}
+
+func verifyTransaction(t *transaction.Transaction, pub []byte, idx uint16) error {
+	if t.Version() != transaction.Genesis || t.Nonce() != uint64(idx) {
+		return ErrInvalidTransaction
+	}
+
+	snd := t.Sender()
+
+	if snd.Version() != address.Genesis || !bytes.Equal(snd.PublicKey().ToBytes(), pub) {
+		return ErrInvalidTransaction
+	}
+
+	if err := checkAllocation(t.Recipient(), t.Amount(), amount.Max); err != nil {
+		return ErrInvalidTransaction
+	}
+
+	if !snd.PublicKey().VerifySignature(t.Signature(), t.Bytes[0:85]) {
+		return ErrInvalidTransaction
+	}
+
+	return nil
+}
+
+func checkAllocation(a *address.Address, v, max amount.Amount) error {
+	if a == nil || a.Verify() != nil || a.Version() == address.Genesis {
+		return ErrInvalidAllocation
+	}
+
+	if v == 0 || !v.Valid() || v > max {
+		return ErrInvalidAllocation
+	}
+
+	return nil
+}
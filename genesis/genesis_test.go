@@ -0,0 +1,220 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package genesis_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/genesis"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/network"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func newConfig() genesis.Config {
+	return genesis.Config{
+		Timestamp: 1590492060,
+		Key:       newKey(),
+		Allocations: []genesis.Allocation{
+			{Address: address.FromKey(newKey()), Value: 1000000},
+			{Address: address.FromKey(newKey()), Value: 42},
+			{Address: address.FromKey(newKey()).SetPrefix("aaa"), Value: 1},
+		},
+	}
+}
+
+func TestNewBlock(t *testing.T) {
+	cfg := newConfig()
+
+	b, err := genesis.NewBlock(cfg)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := genesis.Verify(b); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if act := b.Timestamp(); act != cfg.Timestamp {
+		t.Fatalf("Expected: %d, got: %d", cfg.Timestamp, act)
+	}
+
+	if act := int(b.TxCount()); act != len(cfg.Allocations) {
+		t.Fatalf("Expected: %d, got: %d", len(cfg.Allocations), act)
+	}
+
+	for i, a := range cfg.Allocations {
+		tx := b.Transaction(uint16(i))
+
+		if act := tx.Recipient().ToBech32(); act != a.Address.ToBech32() {
+			t.Fatalf("Expected: %s, got: %s", a.Address.ToBech32(), act)
+		}
+
+		if act := tx.Amount(); act != a.Value {
+			t.Fatalf("Expected: %d, got: %d", a.Value, act)
+		}
+
+		if act := tx.Sender().Prefix(); act != "genesis" {
+			t.Fatalf("Expected: genesis, got: %s", act)
+		}
+	}
+}
+
+func TestNewBlockErrors(t *testing.T) {
+	cases := []struct {
+		desc string
+		mod  func(*genesis.Config)
+		err  error
+	}{
+		{"no key", func(c *genesis.Config) { c.Key = nil }, genesis.ErrNoKey},
+		{"no allocations", func(c *genesis.Config) { c.Allocations = nil }, genesis.ErrNoAllocations},
+		{"zero value", func(c *genesis.Config) { c.Allocations[0].Value = 0 }, genesis.ErrInvalidAllocation},
+		{"too big value", func(c *genesis.Config) { c.Allocations[0].Value = 1 << 60 }, genesis.ErrInvalidAllocation},
+		{"no address", func(c *genesis.Config) { c.Allocations[1].Address = nil }, genesis.ErrInvalidAllocation},
+		{"genesis recipient", func(c *genesis.Config) {
+			c.Allocations[1].Address = genesis.Address(newKey())
+		}, genesis.ErrInvalidAllocation},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := newConfig()
+			tc.mod(&cfg)
+
+			if _, err := genesis.NewBlock(cfg); !errors.Is(err, tc.err) {
+				t.Fatalf("Expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	cfg := newConfig()
+	oth := newKey()
+
+	cases := []struct {
+		desc string
+		mod  func(*block.Block)
+		err  error
+	}{
+		{"previous hash", func(b *block.Block) {
+			b.SetPreviousBlockHash([]byte{1}).Sign(cfg.Key)
+		}, genesis.ErrInvalidBlock},
+		{"merkle root", func(b *block.Block) {
+			b.SetMerkleRootHash(make([]byte, 32)).Sign(cfg.Key)
+		}, genesis.ErrInvalidBlock},
+		{"block signature", func(b *block.Block) {
+			b.Bytes[120] ^= 1
+		}, genesis.ErrInvalidBlock},
+		{"truncated", func(b *block.Block) {
+			b.Bytes = b.Bytes[:len(b.Bytes)-1]
+		}, genesis.ErrInvalidBlock},
+		{"basic transaction", func(b *block.Block) {
+			edit(b, cfg.Key, 0, func(tx *transaction.Transaction) {
+				tx.SetVersion(transaction.Basic).SetSender(address.FromKey(cfg.Key)).Sign(*cfg.Key)
+			})
+		}, genesis.ErrInvalidTransaction},
+		{"foreign sender", func(b *block.Block) {
+			edit(b, cfg.Key, 1, func(tx *transaction.Transaction) {
+				tx.SetSender(genesis.Address(oth)).Sign(*oth)
+			})
+		}, genesis.ErrInvalidTransaction},
+		{"nonce", func(b *block.Block) {
+			edit(b, cfg.Key, 2, func(tx *transaction.Transaction) {
+				tx.SetNonce(0).Sign(*cfg.Key)
+			})
+		}, genesis.ErrInvalidTransaction},
+		{"transaction signature", func(b *block.Block) {
+			edit(b, cfg.Key, 2, func(tx *transaction.Transaction) {
+				tx.SetValue(2)
+			})
+		}, genesis.ErrInvalidTransaction},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			b, _ := genesis.NewBlock(cfg)
+			tc.mod(b)
+
+			if err := genesis.Verify(b); !errors.Is(err, tc.err) {
+				t.Fatalf("Expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+// edit replaces the transaction at idx with its modified copy and re-signs b.
+func edit(b *block.Block, k *key.SecretKey, idx uint16, mod func(*transaction.Transaction)) {
+	tx := b.Transaction(idx)
+	mod(tx)
+
+	off := block.HeaderLength + int(idx)*transaction.Length
+	copy(b.Bytes[off:off+transaction.Length], tx.Bytes)
+	b.SetMerkleRootHash(b.CalculateMerkleRoot()).Sign(k)
+}
+
+func TestVerifyWithParams(t *testing.T) {
+	cfg := newConfig()
+	cfg.Params, _ = network.NewDevnet("local").SetBlockVersion(3).Build()
+
+	b, err := genesis.NewBlock(cfg)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if act := b.Version(); act != 3 {
+		t.Fatalf("Expected: 3, got: %d", act)
+	}
+
+	if err := genesis.VerifyWithParams(b, cfg.Params); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := genesis.VerifyWithParams(b, network.Mainnet()); !errors.Is(err, genesis.ErrInvalidBlock) {
+		t.Fatalf("Expected: %v, got: %v", genesis.ErrInvalidBlock, err)
+	}
+
+	pin, _ := network.NewDevnet("local").SetBlockVersion(3).SetGenesisHash(b.Hash()).Build()
+	if err := genesis.VerifyWithParams(b, pin); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	low, _ := network.NewDevnet("local").SetBlockVersion(3).SetMaxValue(100).Build()
+	if err := genesis.VerifyWithParams(b, low); !errors.Is(err, genesis.ErrInvalidTransaction) {
+		t.Fatalf("Expected: %v, got: %v", genesis.ErrInvalidTransaction, err)
+	}
+
+	cfg.Params = low
+	if _, err := genesis.NewBlock(cfg); !errors.Is(err, genesis.ErrInvalidAllocation) {
+		t.Fatalf("Expected: %v, got: %v", genesis.ErrInvalidAllocation, err)
+	}
+}
@@ -1,3 +1,8 @@
 module github.com/umi-top/umi-core
 
-go 1.14
+go 1.17
+
+require (
+	filippo.io/edwards25519 v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,202 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package key
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+)
+
+const (
+	pemSecretKey = "PRIVATE KEY"
+	pemPublicKey = "PUBLIC KEY"
+)
+
+var (
+	ErrInvalidKey       = errors.New("key: invalid key")
+	ErrUnsupportedKey   = errors.New("key: unsupported key type, expected ed25519")
+	ErrUnsupportedCurve = errors.New("key: unsupported curve, expected Ed25519")
+)
+
+// jwk is an OKP JSON Web Key as defined in RFC 8037.
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	D   string `json:"d,omitempty"`
+}
+
+// MarshalPKCS8 encodes s as a PKCS#8 DER private key.
+func (s *SecretKey) MarshalPKCS8() ([]byte, error) {
+	return x509.MarshalPKCS8PrivateKey(s.key)
+}
+
+func SecretKeyFromPKCS8(der []byte) (*SecretKey, error) {
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	sk, ok := k.(ed25519.PrivateKey)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	return NewSecretKey(sk), nil
+}
+
+// MarshalPKIX encodes p as a PKIX DER public key.
+func (p *PublicKey) MarshalPKIX() ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(p.key)
+}
+
+func PublicKeyFromPKIX(der []byte) (*PublicKey, error) {
+	k, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	pk, ok := k.(ed25519.PublicKey)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	return NewPublicKey(pk), nil
+}
+
+// MarshalPEM encodes s as a "PRIVATE KEY" PEM block.
+func (s *SecretKey) MarshalPEM() ([]byte, error) {
+	der, err := s.MarshalPKCS8()
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemSecretKey, Bytes: der}), nil
+}
+
+func SecretKeyFromPEM(b []byte) (*SecretKey, error) {
+	der, err := decodePEM(b, pemSecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return SecretKeyFromPKCS8(der)
+}
+
+// MarshalPEM encodes p as a "PUBLIC KEY" PEM block.
+func (p *PublicKey) MarshalPEM() ([]byte, error) {
+	der, err := p.MarshalPKIX()
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}), nil
+}
+
+func PublicKeyFromPEM(b []byte) (*PublicKey, error) {
+	der, err := decodePEM(b, pemPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return PublicKeyFromPKIX(der)
+}
+
+func decodePEM(b []byte, typ string) ([]byte, error) {
+	blk, _ := pem.Decode(b)
+	if blk == nil || blk.Type != typ {
+		return nil, ErrInvalidKey
+	}
+
+	return blk.Bytes, nil
+}
+
+// MarshalJWK encodes s as an OKP JWK with the seed as "d".
+func (s *SecretKey) MarshalJWK() ([]byte, error) {
+	return json.Marshal(jwk{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(s.PublicKey().ToBytes()),
+		D:   base64.RawURLEncoding.EncodeToString(s.Seed()),
+	})
+}
+
+// SecretKeyFromJWK decodes an OKP JWK, "x" must match the key derived
+// from "d".
+func SecretKeyFromJWK(b []byte) (*SecretKey, error) {
+	j, x, err := decodeJWK(b)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := base64.RawURLEncoding.DecodeString(j.D)
+	if err != nil || len(d) != SeedLength {
+		return nil, ErrInvalidKey
+	}
+
+	s := NewSecretKeyFromSeed(d)
+	if string(s.PublicKey().ToBytes()) != string(x) {
+		return nil, ErrInvalidKey
+	}
+
+	return s, nil
+}
+
+// MarshalJWK encodes p as an OKP JWK.
+func (p *PublicKey) MarshalJWK() ([]byte, error) {
+	return json.Marshal(jwk{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(p.key)})
+}
+
+// PublicKeyFromJWK decodes the public part of an OKP JWK.
+func PublicKeyFromJWK(b []byte) (*PublicKey, error) {
+	_, x, err := decodeJWK(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPublicKey(x), nil
+}
+
+func decodeJWK(b []byte) (*jwk, []byte, error) {
+	j := &jwk{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return nil, nil, ErrInvalidKey
+	}
+
+	if j.Kty != "OKP" {
+		return nil, nil, ErrUnsupportedKey
+	}
+
+	if j.Crv != "Ed25519" {
+		return nil, nil, ErrUnsupportedCurve
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(j.X)
+	if err != nil || len(x) != ed25519.PublicKeySize {
+		return nil, nil, ErrInvalidKey
+	}
+
+	return j, x, nil
+}
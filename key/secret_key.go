@@ -24,6 +24,8 @@ import (
 	"crypto/ed25519"
 )
 
+const SeedLength = ed25519.SeedSize
+
 type SecretKey struct {
 	key ed25519.PrivateKey
 }
@@ -35,6 +37,14 @@ func NewSecretKey(b []byte) *SecretKey {
 	return &SecretKey{key: key}
 }
 
+// NewSecretKeyFromSeed derives the secret key from a 32-byte seed.
+func NewSecretKeyFromSeed(b []byte) *SecretKey {
+	seed := make([]byte, SeedLength)
+	copy(seed, b)
+
+	return &SecretKey{key: ed25519.NewKeyFromSeed(seed)}
+}
+
 func (s *SecretKey) PublicKey() *PublicKey {
 	return &PublicKey{key: s.key.Public().(ed25519.PublicKey)}
 }
@@ -49,3 +59,7 @@ func (s *SecretKey) ToBytes() []byte {
 
 	return b
 }
+
+func (s *SecretKey) Seed() []byte {
+	return s.key.Seed()
+}
@@ -79,3 +79,19 @@ func TestSecKeySign(t *testing.T) {
 		})
 	}
 }
+
+func TestSecKeySeed(t *testing.T) {
+	exp, _ := base64.StdEncoding.DecodeString(
+		"u1mzvCnmyIbgs8RNM9GGGHOWcBdMvD7GIKC0m9zTFcaGXaAPQMbuPdZ1oAnTCfR/1rHTyC3J5n7x+dlFimHM8w==")
+	seed := key.NewSecretKey(exp).Seed()
+
+	if !bytes.Equal(exp[:key.SeedLength], seed) {
+		t.Fatalf("Expected: %x, got: %x", exp[:key.SeedLength], seed)
+	}
+
+	act := key.NewSecretKeyFromSeed(seed).ToBytes()
+
+	if !bytes.Equal(exp, act) {
+		t.Fatalf("Expected: %x, got: %x", exp, act)
+	}
+}
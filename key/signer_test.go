@@ -0,0 +1,50 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package key_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/umi-top/umi-core/key"
+)
+
+func TestNewSigner(t *testing.T) {
+	_, b, _ := ed25519.GenerateKey(nil)
+	sec := key.NewSecretKey(b)
+	msg := []byte("message")
+
+	s := key.NewSigner(sec)
+
+	sig, err := s.Sign(msg)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if !bytes.Equal(sig, sec.Sign(msg)) {
+		t.Fatalf("Expected: %x, got: %x", sec.Sign(msg), sig)
+	}
+
+	if !bytes.Equal(s.PublicKey().ToBytes(), sec.PublicKey().ToBytes()) {
+		t.Fatalf("Expected: %x, got: %x", sec.PublicKey().ToBytes(), s.PublicKey().ToBytes())
+	}
+}
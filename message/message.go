@@ -0,0 +1,181 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package message signs off-chain messages, such as login challenges, with
+// UMI keys.
+//
+// The signed payload is Tag, the 34-byte signer address and the SHA-256 of
+// the domain and the message body, 86 bytes in total. Transactions sign 85
+// bytes and block headers 103 bytes, both starting with a small version
+// number, so a message signature is never valid for either of them.
+package message
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/key"
+)
+
+// Tag starts every signed payload, 0xFF is not a valid version.
+const Tag = "\xffUMI Signed Message\n"
+
+const PayloadLength = len(Tag) + address.Length + sha256.Size
+
+var (
+	ErrKeyMismatch      = errors.New("message: key does not match address")
+	ErrInvalidDomain    = errors.New("message: invalid domain")
+	ErrInvalidSignature = errors.New("message: invalid signature")
+)
+
+// Message is a body signed for a domain, such as the host name of the
+// service that asked for the signature.
+type Message struct {
+	Domain    string
+	Address   *address.Address
+	Body      []byte
+	Signature []byte
+}
+
+type jsonMessage struct {
+	Domain    string `json:"domain"`
+	Address   string `json:"address"`
+	Body      []byte `json:"body"`
+	Signature string `json:"signature"`
+}
+
+// Sign signs body for domain with k, a is the signer address and must hold
+// the public key of k.
+func Sign(k *key.SecretKey, a *address.Address, domain string, body []byte) (*Message, error) {
+	if err := a.Verify(); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(a.PublicKey().ToBytes(), k.PublicKey().ToBytes()) {
+		return nil, ErrKeyMismatch
+	}
+
+	m := &Message{Domain: domain, Address: address.FromBytes(a.Bytes), Body: append([]byte(nil), body...)}
+
+	p, err := m.Payload()
+	if err != nil {
+		return nil, err
+	}
+
+	m.Signature = k.Sign(p)
+
+	return m, nil
+}
+
+// Payload returns the bytes that are signed.
+func (m *Message) Payload() ([]byte, error) {
+	if len(m.Domain) > 0xFFFF {
+		return nil, ErrInvalidDomain
+	}
+
+	h := sha256.New()
+	_ = binary.Write(h, binary.BigEndian, uint16(len(m.Domain)))
+	_, _ = h.Write([]byte(m.Domain))
+	_, _ = h.Write(m.Body)
+
+	p := make([]byte, 0, PayloadLength)
+	p = append(p, Tag...)
+	p = append(p, m.Address.Bytes...)
+	p = append(p, h.Sum(nil)...)
+
+	return p, nil
+}
+
+// Verify checks the signature against the public key of the address.
+func (m *Message) Verify() error {
+	if m.Address == nil {
+		return address.ErrInvalidAddress
+	}
+
+	if err := m.Address.Verify(); err != nil {
+		return err
+	}
+
+	p, err := m.Payload()
+	if err != nil {
+		return err
+	}
+
+	if len(m.Signature) != 64 || !m.Address.PublicKey().VerifySignature(m.Signature, p) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// VerifyAddress checks that sig signs body for domain by the bech32
+// address bech.
+func VerifyAddress(bech, domain string, body, sig []byte) error {
+	a, err := address.ParseBech32(bech)
+	if err != nil {
+		return err
+	}
+
+	return (&Message{Domain: domain, Address: a, Body: body, Signature: sig}).Verify()
+}
+
+func (m *Message) MarshalJSON() ([]byte, error) {
+	if m.Address == nil {
+		return nil, address.ErrInvalidAddress
+	}
+
+	if err := m.Address.Verify(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonMessage{
+		Domain:    m.Domain,
+		Address:   m.Address.ToBech32(),
+		Body:      m.Body,
+		Signature: hex.EncodeToString(m.Signature),
+	})
+}
+
+// UnmarshalJSON decodes a message without verifying it.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var j jsonMessage
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	a, err := address.ParseBech32(j.Address)
+	if err != nil {
+		return err
+	}
+
+	sig, err := hex.DecodeString(j.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	*m = Message{Domain: j.Domain, Address: a, Body: j.Body, Signature: sig}
+
+	return nil
+}
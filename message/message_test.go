@@ -0,0 +1,136 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package message_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/message"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func TestSignVerify(t *testing.T) {
+	k := newKey()
+	a := address.FromKey(k)
+	body := []byte("login challenge 8f1c")
+
+	m, err := message.Sign(k, a, "example.com", body)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := m.Verify(); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := message.VerifyAddress(a.ToBech32(), "example.com", body, m.Signature); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	// Signing is deterministic.
+	if m2, _ := message.Sign(k, a, "example.com", body); string(m2.Signature) != string(m.Signature) {
+		t.Fatalf("Expected: equal signatures")
+	}
+
+	cases := []struct {
+		desc   string
+		bech   string
+		domain string
+		body   string
+	}{
+		{"domain", a.ToBech32(), "evil.com", string(body)},
+		{"body", a.ToBech32(), "example.com", "login challenge 8f1d"},
+		{"address prefix", address.FromKey(k).SetPrefix("aaa").ToBech32(), "example.com", string(body)},
+		{"signer", address.FromKey(newKey()).ToBech32(), "example.com", string(body)},
+		{"domain shifted into body", a.ToBech32(), "example.co", "m" + string(body)},
+	}
+
+	for _, tc := range cases {
+		if err := message.VerifyAddress(tc.bech, tc.domain, []byte(tc.body), m.Signature); !errors.Is(err, message.ErrInvalidSignature) {
+			t.Fatalf("%s: Expected: %v, got: %v", tc.desc, message.ErrInvalidSignature, err)
+		}
+	}
+
+	if _, err := message.Sign(newKey(), a, "example.com", body); !errors.Is(err, message.ErrKeyMismatch) {
+		t.Fatalf("Expected: %v, got: %v", message.ErrKeyMismatch, err)
+	}
+}
+
+func TestDomainSeparation(t *testing.T) {
+	k := newKey()
+	a := address.FromKey(k)
+
+	m, _ := message.Sign(k, a, "", nil)
+	p, _ := m.Payload()
+
+	if len(p) != message.PayloadLength || len(p) == 85 || len(p) == block.HeaderLength-64 {
+		t.Fatalf("Expected: payload length distinct from transactions and blocks, got: %d", len(p))
+	}
+
+	// Replaying the signature as a transaction or block signature fails.
+	tx := transaction.FromBytes(append(p[:85:85], make([]byte, 65)...)).SetSignature(m.Signature)
+	if err := tx.Verify(); err == nil {
+		t.Fatalf("Expected: transaction to be rejected")
+	}
+
+	b := block.NewBlock()
+	copy(b.Bytes, p)
+	copy(b.Bytes[71:103], k.PublicKey().ToBytes())
+	copy(b.Bytes[103:167], m.Signature)
+
+	if b.Verify() {
+		t.Fatalf("Expected: block to be rejected")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	k := newKey()
+	m, _ := message.Sign(k, address.FromKey(k).SetPrefix("aaa"), "example.com", []byte("hello"))
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	var act message.Message
+	if err := json.Unmarshal(b, &act); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := act.Verify(); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if act.Address.Prefix() != "aaa" || string(act.Body) != "hello" {
+		t.Fatalf("unexpected message: %+v", act)
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package multisig authorizes transactions with M of N ed25519 keys.
+//
+// The chain accepts a single signature per transaction, so co-signing
+// happens off-chain: every member signs the policy ID together with the
+// unsigned transaction, the partial signatures are aggregated into an
+// Authorization, and the service holding the on-chain key verifies it
+// against the Policy before signing and submitting the transaction.
+package multisig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+// MaxKeys is the largest number of keys in a policy.
+const MaxKeys = 255
+
+const (
+	policyDomain  = "umi-multisig-policy-v1"
+	messageDomain = "umi-multisig-message-v1"
+	partialLength = 32 + 64
+)
+
+var (
+	ErrInvalidPolicy        = errors.New("multisig: invalid policy")
+	ErrInvalidAuthorization = errors.New("multisig: invalid authorization")
+	ErrPolicyMismatch       = errors.New("multisig: authorization is for another policy")
+	ErrNotMember            = errors.New("multisig: key is not a member of the policy")
+	ErrDuplicateSigner      = errors.New("multisig: duplicate signer")
+	ErrInvalidSignature     = errors.New("multisig: invalid signature")
+	ErrThreshold            = errors.New("multisig: not enough signatures")
+	ErrUnauthorized         = errors.New("multisig: authorization required")
+)
+
+// Policy requires Threshold signatures from distinct Keys. Keys are kept in
+// ascending byte order, so equal policies have equal IDs.
+type Policy struct {
+	Threshold int
+	Keys      []*key.PublicKey
+}
+
+func NewPolicy(threshold int, keys ...*key.PublicKey) (*Policy, error) {
+	p := &Policy{Threshold: threshold, Keys: make([]*key.PublicKey, len(keys))}
+	copy(p.Keys, keys)
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(p.Keys, func(i, j int) bool {
+		return bytes.Compare(p.Keys[i].ToBytes(), p.Keys[j].ToBytes()) < 0
+	})
+
+	return p, nil
+}
+
+// Validate reports whether p has between 1 and MaxKeys distinct keys and a
+// threshold between 1 and the number of keys.
+func (p *Policy) Validate() error {
+	if len(p.Keys) == 0 || len(p.Keys) > MaxKeys || p.Threshold < 1 || p.Threshold > len(p.Keys) {
+		return ErrInvalidPolicy
+	}
+
+	seen := make(map[string]struct{}, len(p.Keys))
+
+	for _, k := range p.Keys {
+		if k == nil {
+			return ErrInvalidPolicy
+		}
+
+		if _, ok := seen[string(k.ToBytes())]; ok {
+			return ErrInvalidPolicy
+		}
+
+		seen[string(k.ToBytes())] = struct{}{}
+	}
+
+	return nil
+}
+
+// ID identifies the threshold and the set of keys.
+func (p *Policy) ID() []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte(policyDomain))
+
+	var n [4]byte
+
+	binary.BigEndian.PutUint16(n[0:2], uint16(p.Threshold))
+	binary.BigEndian.PutUint16(n[2:4], uint16(len(p.Keys)))
+	_, _ = h.Write(n[:])
+
+	for _, k := range p.Keys {
+		_, _ = h.Write(k.ToBytes())
+	}
+
+	return h.Sum(nil)
+}
+
+// Message returns the bytes members sign to approve t.
+func (p *Policy) Message(t *transaction.Transaction) []byte {
+	m := make([]byte, 0, len(messageDomain)+32+85)
+	m = append(m, messageDomain...)
+	m = append(m, p.ID()...)
+	m = append(m, t.Bytes[0:85]...)
+
+	return m
+}
+
+// Partial is the approval of t by one member.
+type Partial struct {
+	PublicKey *key.PublicKey
+	Signature []byte
+}
+
+// SignPartial approves t with k.
+func (p *Policy) SignPartial(t *transaction.Transaction, k *key.SecretKey) (Partial, error) {
+	if p.index(k.PublicKey()) < 0 {
+		return Partial{}, ErrNotMember
+	}
+
+	return Partial{PublicKey: k.PublicKey(), Signature: k.Sign(p.Message(t))}, nil
+}
+
+// Authorization is a set of partial signatures for one policy.
+type Authorization struct {
+	PolicyID []byte
+	Partials []Partial
+}
+
+// Aggregate checks the partial signatures for t and combines them, in key
+// order, into an Authorization.
+func (p *Policy) Aggregate(t *transaction.Transaction, parts ...Partial) (*Authorization, error) {
+	a := &Authorization{PolicyID: p.ID(), Partials: make([]Partial, len(parts))}
+	copy(a.Partials, parts)
+
+	sort.Slice(a.Partials, func(i, j int) bool {
+		return p.index(a.Partials[i].PublicKey) < p.index(a.Partials[j].PublicKey)
+	})
+
+	if err := p.Verify(t, a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Verify checks that a holds at least Threshold valid signatures of t by
+// distinct members of p.
+func (p *Policy) Verify(t *transaction.Transaction, a *Authorization) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(a.PolicyID, p.ID()) {
+		return ErrPolicyMismatch
+	}
+
+	msg := p.Message(t)
+	seen := make(map[int]struct{}, len(a.Partials))
+
+	for _, s := range a.Partials {
+		if s.PublicKey == nil {
+			return ErrInvalidAuthorization
+		}
+
+		i := p.index(s.PublicKey)
+		if i < 0 {
+			return ErrNotMember
+		}
+
+		if _, ok := seen[i]; ok {
+			return ErrDuplicateSigner
+		}
+
+		if len(s.Signature) != 64 || !s.PublicKey.VerifySignature(s.Signature, msg) {
+			return ErrInvalidSignature
+		}
+
+		seen[i] = struct{}{}
+	}
+
+	if len(seen) < p.Threshold {
+		return ErrThreshold
+	}
+
+	return nil
+}
+
+func (p *Policy) index(k *key.PublicKey) int {
+	if k == nil {
+		return -1
+	}
+
+	b := k.ToBytes()
+
+	for i, m := range p.Keys {
+		if bytes.Equal(m.ToBytes(), b) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// MarshalBinary encodes a as the policy ID, the number of signatures and
+// every public key followed by its signature.
+func (a *Authorization) MarshalBinary() ([]byte, error) {
+	if len(a.PolicyID) != 32 || len(a.Partials) > MaxKeys {
+		return nil, ErrInvalidAuthorization
+	}
+
+	b := make([]byte, 0, 33+len(a.Partials)*partialLength)
+	b = append(b, a.PolicyID...)
+	b = append(b, uint8(len(a.Partials)))
+
+	for _, s := range a.Partials {
+		if s.PublicKey == nil || len(s.Signature) != 64 {
+			return nil, ErrInvalidAuthorization
+		}
+
+		b = append(b, s.PublicKey.ToBytes()...)
+		b = append(b, s.Signature...)
+	}
+
+	return b, nil
+}
+
+func (a *Authorization) UnmarshalBinary(b []byte) error {
+	if len(b) < 33 || len(b) != 33+int(b[32])*partialLength {
+		return ErrInvalidAuthorization
+	}
+
+	a.PolicyID = append([]byte(nil), b[0:32]...)
+	a.Partials = make([]Partial, b[32])
+
+	for i := range a.Partials {
+		off := 33 + i*partialLength
+		a.Partials[i] = Partial{
+			PublicKey: key.NewPublicKey(b[off : off+32]),
+			Signature: append([]byte(nil), b[off+32:off+partialLength]...),
+		}
+	}
+
+	return nil
+}
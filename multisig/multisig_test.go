@@ -0,0 +1,240 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package multisig_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/multisig"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func newPolicy(t *testing.T, m, n int) (*multisig.Policy, []*key.SecretKey) {
+	keys := make([]*key.SecretKey, n)
+	pubs := make([]*key.PublicKey, n)
+
+	for i := range keys {
+		keys[i] = newKey()
+		pubs[i] = keys[i].PublicKey()
+	}
+
+	p, err := multisig.NewPolicy(m, pubs...)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	return p, keys
+}
+
+func updateFee(own *key.SecretKey) *transaction.Transaction {
+	return transaction.NewTransaction().SetVersion(transaction.UpdateFeeAddress).SetSender(address.FromKey(own)).
+		SetRecipient(address.FromKey(newKey()).SetPrefix("aaa"))
+}
+
+func TestNewPolicy(t *testing.T) {
+	a, b := newKey().PublicKey(), newKey().PublicKey()
+
+	cases := []struct {
+		m    int
+		keys []*key.PublicKey
+		err  error
+	}{
+		{1, []*key.PublicKey{a}, nil},
+		{2, []*key.PublicKey{a, b}, nil},
+		{0, []*key.PublicKey{a, b}, multisig.ErrInvalidPolicy},
+		{3, []*key.PublicKey{a, b}, multisig.ErrInvalidPolicy},
+		{1, nil, multisig.ErrInvalidPolicy},
+		{1, []*key.PublicKey{a, a}, multisig.ErrInvalidPolicy},
+	}
+
+	for i, tc := range cases {
+		if _, err := multisig.NewPolicy(tc.m, tc.keys...); !errors.Is(err, tc.err) {
+			t.Fatalf("%d: Expected: %v, got: %v", i, tc.err, err)
+		}
+	}
+
+	p1, _ := multisig.NewPolicy(1, a, b)
+	p2, _ := multisig.NewPolicy(1, b, a)
+	p3, _ := multisig.NewPolicy(2, a, b)
+
+	if !bytes.Equal(p1.ID(), p2.ID()) {
+		t.Fatalf("Expected: key order not to change the policy ID")
+	}
+
+	if bytes.Equal(p1.ID(), p3.ID()) {
+		t.Fatalf("Expected: threshold to change the policy ID")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	p, keys := newPolicy(t, 2, 3)
+	tx := updateFee(newKey())
+
+	s0, _ := p.SignPartial(tx, keys[0])
+	s2, _ := p.SignPartial(tx, keys[2])
+
+	if _, err := p.SignPartial(tx, newKey()); !errors.Is(err, multisig.ErrNotMember) {
+		t.Fatalf("Expected: %v, got: %v", multisig.ErrNotMember, err)
+	}
+
+	a, err := p.Aggregate(tx, s2, s0)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	b, _ := a.MarshalBinary()
+	c := &multisig.Authorization{}
+
+	if err := c.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := p.Verify(tx, c); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := c.UnmarshalBinary(b[:len(b)-1]); !errors.Is(err, multisig.ErrInvalidAuthorization) {
+		t.Fatalf("Expected: %v, got: %v", multisig.ErrInvalidAuthorization, err)
+	}
+
+	// The approval covers the exact transaction.
+	if err := p.Verify(transaction.FromBytes(tx.Bytes).SetNonce(1), a); !errors.Is(err, multisig.ErrInvalidSignature) {
+		t.Fatalf("Expected: %v, got: %v", multisig.ErrInvalidSignature, err)
+	}
+
+	// A signature by the same member under another policy is rejected.
+	o, _ := multisig.NewPolicy(1, keys[0].PublicKey())
+	foreign, _ := o.SignPartial(tx, keys[0])
+
+	cases := []struct {
+		desc  string
+		parts []multisig.Partial
+		err   error
+	}{
+		{"one of two", []multisig.Partial{s0}, multisig.ErrThreshold},
+		{"duplicate", []multisig.Partial{s0, s0}, multisig.ErrDuplicateSigner},
+		{"stranger", []multisig.Partial{s0, {PublicKey: newKey().PublicKey(), Signature: s2.Signature}}, multisig.ErrNotMember},
+		{"other policy", []multisig.Partial{foreign, s2}, multisig.ErrInvalidSignature},
+		{"no key", []multisig.Partial{s0, {}}, multisig.ErrInvalidAuthorization},
+	}
+
+	for _, tc := range cases {
+		if _, err := p.Aggregate(tx, tc.parts...); !errors.Is(err, tc.err) {
+			t.Fatalf("%s: Expected: %v, got: %v", tc.desc, tc.err, err)
+		}
+	}
+
+	if err := o.Verify(tx, a); !errors.Is(err, multisig.ErrPolicyMismatch) {
+		t.Fatalf("Expected: %v, got: %v", multisig.ErrPolicyMismatch, err)
+	}
+}
+
+func TestVerifyInvalidPolicy(t *testing.T) {
+	p, keys := newPolicy(t, 1, 2)
+	tx := updateFee(newKey())
+
+	cases := []struct {
+		desc string
+		p    *multisig.Policy
+	}{
+		{"zero threshold", &multisig.Policy{Threshold: 0, Keys: p.Keys}},
+		{"negative threshold", &multisig.Policy{Threshold: -1, Keys: p.Keys}},
+		{"threshold above keys", &multisig.Policy{Threshold: 3, Keys: p.Keys}},
+		{"duplicate keys", &multisig.Policy{Threshold: 2, Keys: []*key.PublicKey{p.Keys[0], p.Keys[0]}}},
+		{"no keys", &multisig.Policy{Threshold: 1}},
+	}
+
+	for _, tc := range cases {
+		a := &multisig.Authorization{PolicyID: tc.p.ID()}
+		if err := tc.p.Verify(tx, a); !errors.Is(err, multisig.ErrInvalidPolicy) {
+			t.Fatalf("%s: Expected: %v, got: %v", tc.desc, multisig.ErrInvalidPolicy, err)
+		}
+	}
+
+	s0, _ := p.SignPartial(tx, keys[0])
+	if _, err := p.Aggregate(tx, s0); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	p, keys := newPolicy(t, 2, 2)
+	own := newKey()
+	tx := updateFee(own)
+
+	s0, _ := p.SignPartial(tx, keys[0])
+	s1, _ := p.SignPartial(tx, keys[1])
+	a, _ := p.Aggregate(tx, s0, s1)
+
+	v := multisig.NewVerifier()
+
+	if err := v.Check(tx, nil); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := v.SetPolicy("aaa", p); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := v.Check(tx, nil); !errors.Is(err, multisig.ErrUnauthorized) {
+		t.Fatalf("Expected: %v, got: %v", multisig.ErrUnauthorized, err)
+	}
+
+	if err := v.Check(tx, a); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	update := transaction.NewTransaction().SetVersion(transaction.UpdateSmartContract).
+		SetSender(address.FromKey(own)).SetPrefix("aaa")
+	if err := v.Check(update, a); !errors.Is(err, multisig.ErrInvalidSignature) {
+		t.Fatalf("Expected: %v, got: %v", multisig.ErrInvalidSignature, err)
+	}
+
+	basic := transaction.NewTransaction().SetSender(address.FromKey(own)).
+		SetRecipient(address.FromKey(newKey()).SetPrefix("aaa"))
+	if err := v.Check(basic, nil); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := v.SetPolicy("aaa", &multisig.Policy{Keys: p.Keys}); !errors.Is(err, multisig.ErrInvalidPolicy) {
+		t.Fatalf("Expected: %v, got: %v", multisig.ErrInvalidPolicy, err)
+	}
+
+	if err := v.Check(tx, nil); !errors.Is(err, multisig.ErrUnauthorized) {
+		t.Fatalf("Expected: %v, got: %v", multisig.ErrUnauthorized, err)
+	}
+
+	_ = v.SetPolicy("aaa", nil)
+
+	if err := v.Check(tx, nil); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+}
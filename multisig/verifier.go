@@ -0,0 +1,88 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package multisig
+
+import (
+	"sync"
+
+	"github.com/umi-top/umi-core/transaction"
+)
+
+// Verifier enforces a policy per structure prefix on structure
+// administration transactions. Transfers are not affected.
+type Verifier struct {
+	mu       sync.RWMutex
+	policies map[string]*Policy
+}
+
+func NewVerifier() *Verifier {
+	return &Verifier{policies: make(map[string]*Policy)}
+}
+
+// SetPolicy requires p for changes of the structure, a nil p removes the
+// requirement. An invalid p is rejected and the previous policy is kept.
+func (v *Verifier) SetPolicy(prefix string, p *Policy) error {
+	if p != nil {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if p == nil {
+		delete(v.policies, prefix)
+	} else {
+		v.policies[prefix] = p
+	}
+
+	return nil
+}
+
+// Check verifies a against the policy of the structure t changes.
+func (v *Verifier) Check(t *transaction.Transaction, a *Authorization) error {
+	var prefix string
+
+	switch t.Version() {
+	case transaction.CreateSmartContract, transaction.UpdateSmartContract:
+		prefix = t.Prefix()
+	case transaction.UpdateProfitAddress, transaction.UpdateFeeAddress,
+		transaction.CreateTransitAddress, transaction.DeleteTransitAddress:
+		prefix = t.Recipient().Prefix()
+	default:
+		return nil
+	}
+
+	v.mu.RLock()
+	p, ok := v.policies[prefix]
+	v.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	if a == nil {
+		return ErrUnauthorized
+	}
+
+	return p.Verify(t, a)
+}
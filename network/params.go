@@ -0,0 +1,175 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package network describes the consensus parameters of a UMI network.
+package network
+
+import (
+	"errors"
+
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/util"
+)
+
+var (
+	ErrInvalidName          = errors.New("network: invalid name")
+	ErrInvalidPrefix        = errors.New("network: invalid default prefix")
+	ErrInvalidMaxValue      = errors.New("network: invalid max value")
+	ErrInvalidPercentBounds = errors.New("network: invalid percent bounds")
+	ErrInvalidBlockVersion  = errors.New("network: invalid block version")
+	ErrInvalidGenesisHash   = errors.New("network: invalid genesis hash")
+)
+
+// Params holds the values transactions, blocks and addresses are validated
+// against. Percents are in hundredths of a percent (100 is 1%).
+// A nil GenesisHash means the genesis block is not pinned.
+type Params struct {
+	Name             string
+	DefaultPrefix    string
+	MaxValue         amount.Amount
+	MinProfitPercent uint16
+	MaxProfitPercent uint16
+	MaxFeePercent    uint16
+	BlockVersion     uint8
+	GenesisHash      []byte
+}
+
+var mainnet = Params{
+	Name:             "mainnet",
+	DefaultPrefix:    util.UmiPrefix,
+	MaxValue:         amount.Max,
+	MinProfitPercent: 100,
+	MaxProfitPercent: 500,
+	MaxFeePercent:    2000,
+	BlockVersion:     1,
+}
+
+// testnet shares the consensus values of mainnet so that transactions and
+// blocks are validated the same way before they reach mainnet.
+var testnet = Params{
+	Name:             "testnet",
+	DefaultPrefix:    util.UmiPrefix,
+	MaxValue:         amount.Max,
+	MinProfitPercent: 100,
+	MaxProfitPercent: 500,
+	MaxFeePercent:    2000,
+	BlockVersion:     1,
+}
+
+// Mainnet returns a copy of the mainnet parameters, changing it does not
+// affect other callers.
+func Mainnet() *Params {
+	return mainnet.clone()
+}
+
+// Testnet returns a copy of the testnet parameters.
+func Testnet() *Params {
+	return testnet.clone()
+}
+
+func (p *Params) clone() *Params {
+	c := *p
+	if p.GenesisHash != nil {
+		c.GenesisHash = append([]byte(nil), p.GenesisHash...)
+	}
+
+	return &c
+}
+
+// Validate reports whether p is internally consistent.
+func (p *Params) Validate() error {
+	if p.Name == "" {
+		return ErrInvalidName
+	}
+
+	if _, err := util.ParsePrefix(p.DefaultPrefix); err != nil || p.DefaultPrefix == util.GenesisPrefix {
+		return ErrInvalidPrefix
+	}
+
+	if p.MaxValue == 0 || !p.MaxValue.Valid() {
+		return ErrInvalidMaxValue
+	}
+
+	if p.MinProfitPercent > p.MaxProfitPercent || p.MaxProfitPercent > 10000 || p.MaxFeePercent > 10000 {
+		return ErrInvalidPercentBounds
+	}
+
+	if p.BlockVersion == 0 {
+		return ErrInvalidBlockVersion
+	}
+
+	if p.GenesisHash != nil && len(p.GenesisHash) != 32 {
+		return ErrInvalidGenesisHash
+	}
+
+	return nil
+}
+
+// Builder assembles Params for a local development network, starting from
+// the mainnet values.
+type Builder struct {
+	p Params
+}
+
+func NewDevnet(name string) *Builder {
+	b := &Builder{p: *Mainnet()}
+	b.p.Name = name
+
+	return b
+}
+
+func (b *Builder) SetDefaultPrefix(p string) *Builder {
+	b.p.DefaultPrefix = p
+	return b
+}
+
+func (b *Builder) SetMaxValue(v amount.Amount) *Builder {
+	b.p.MaxValue = v
+	return b
+}
+
+func (b *Builder) SetProfitPercent(min, max uint16) *Builder {
+	b.p.MinProfitPercent, b.p.MaxProfitPercent = min, max
+	return b
+}
+
+func (b *Builder) SetMaxFeePercent(v uint16) *Builder {
+	b.p.MaxFeePercent = v
+	return b
+}
+
+func (b *Builder) SetBlockVersion(v uint8) *Builder {
+	b.p.BlockVersion = v
+	return b
+}
+
+func (b *Builder) SetGenesisHash(h []byte) *Builder {
+	b.p.GenesisHash = append([]byte(nil), h...)
+	return b
+}
+
+// Build validates and returns a copy of the accumulated parameters.
+func (b *Builder) Build() (*Params, error) {
+	if err := b.p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return b.p.clone(), nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package network_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/network"
+)
+
+func TestPresets(t *testing.T) {
+	for _, preset := range []func() *network.Params{network.Mainnet, network.Testnet} {
+		p := preset()
+		if err := p.Validate(); err != nil {
+			t.Fatalf("%s: Expected: nil, got: %v", p.Name, err)
+		}
+
+		p.MaxValue = 1
+		if preset().MaxValue == 1 {
+			t.Fatalf("%s: Expected: a copy", p.Name)
+		}
+	}
+
+	if network.Mainnet().Name != "mainnet" || network.Testnet().Name != "testnet" {
+		t.Fatalf("Expected: mainnet and testnet, got: %s and %s", network.Mainnet().Name, network.Testnet().Name)
+	}
+}
+
+func TestDevnet(t *testing.T) {
+	hsh := make([]byte, 32)
+
+	p, err := network.NewDevnet("local").SetDefaultPrefix("dev").SetMaxValue(1000).
+		SetProfitPercent(0, 10000).SetMaxFeePercent(0).SetBlockVersion(2).SetGenesisHash(hsh).Build()
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if p.Name != "local" || p.DefaultPrefix != "dev" || p.MaxValue != 1000 || p.BlockVersion != 2 {
+		t.Fatalf("unexpected params: %+v", p)
+	}
+
+	hsh[0] = 1
+	if p.GenesisHash[0] != 0 {
+		t.Fatalf("Expected: genesis hash to be copied")
+	}
+
+	if network.Mainnet().DefaultPrefix != "umi" || network.Mainnet().BlockVersion != 1 {
+		t.Fatalf("Expected: mainnet to be unchanged, got: %+v", network.Mainnet())
+	}
+}
+
+func TestDevnetErrors(t *testing.T) {
+	cases := []struct {
+		desc string
+		bld  *network.Builder
+		err  error
+	}{
+		{"name", network.NewDevnet(""), network.ErrInvalidName},
+		{"prefix", network.NewDevnet("x").SetDefaultPrefix("UMI"), network.ErrInvalidPrefix},
+		{"genesis prefix", network.NewDevnet("x").SetDefaultPrefix("genesis"), network.ErrInvalidPrefix},
+		{"zero max value", network.NewDevnet("x").SetMaxValue(0), network.ErrInvalidMaxValue},
+		{"max value", network.NewDevnet("x").SetMaxValue(1 << 60), network.ErrInvalidMaxValue},
+		{"profit bounds", network.NewDevnet("x").SetProfitPercent(500, 100), network.ErrInvalidPercentBounds},
+		{"fee bound", network.NewDevnet("x").SetMaxFeePercent(10001), network.ErrInvalidPercentBounds},
+		{"block version", network.NewDevnet("x").SetBlockVersion(0), network.ErrInvalidBlockVersion},
+		{"genesis hash", network.NewDevnet("x").SetGenesisHash([]byte{1}), network.ErrInvalidGenesisHash},
+	}
+
+	for _, tc := range cases {
+		if _, err := tc.bld.Build(); !errors.Is(err, tc.err) {
+			t.Fatalf("%s: Expected: %v, got: %v", tc.desc, tc.err, err)
+		}
+	}
+}
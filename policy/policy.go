@@ -0,0 +1,542 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package policy evaluates declarative signing rules against transactions.
+//
+// A policy is a list of rules with an effect, and optional "when" and
+// "unless" conditions. A rule applies when its "when" condition matches and
+// its "unless" condition does not. Any applicable deny rule denies the
+// transaction, otherwise any applicable allow rule allows it, otherwise the
+// default effect decides:
+//
+//	{
+//	  "default": "allow",
+//	  "rules": [
+//	    {"name": "basic only", "effect": "deny", "unless": {"versions": ["basic"]}},
+//	    {"name": "limit", "effect": "deny", "when": {"value": {"min": "1000.00"}}},
+//	    {"name": "allowlist", "effect": "deny", "unless": {"recipients": ["umi1..."]}}
+//	  ]
+//	}
+//
+// Within a condition every set field must match and a list matches if any
+// of its entries does. A condition without any field set and unknown
+// fields are rejected, so a misspelled field can not turn a rule into one
+// that always or never applies.
+//
+// Parse reads the JSON form and ParseYAML the same structure written as
+// YAML:
+//
+//	default: allow
+//	rules:
+//	  - name: limit
+//	    effect: deny
+//	    when: {value: {min: "1000.00"}}
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/transaction"
+	"github.com/umi-top/umi-core/util"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+var (
+	ErrInvalidPolicy = errors.New("policy: invalid policy")
+	ErrDenied        = errors.New("policy: denied")
+)
+
+var versions = map[string]uint8{
+	"genesis":              transaction.Genesis,
+	"basic":                transaction.Basic,
+	"createStructure":      transaction.CreateSmartContract,
+	"updateStructure":      transaction.UpdateSmartContract,
+	"updateProfitAddress":  transaction.UpdateProfitAddress,
+	"updateFeeAddress":     transaction.UpdateFeeAddress,
+	"createTransitAddress": transaction.CreateTransitAddress,
+	"deleteTransitAddress": transaction.DeleteTransitAddress,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Policy is the declarative form of a rule set. Default is Allow when empty.
+type Policy struct {
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+	Rules   []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule is reported by Name, or by Reason when it is set.
+type Rule struct {
+	Name   string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Effect string     `json:"effect" yaml:"effect"`
+	Reason string     `json:"reason,omitempty" yaml:"reason,omitempty"`
+	When   *Condition `json:"when,omitempty" yaml:"when,omitempty"`
+	Unless *Condition `json:"unless,omitempty" yaml:"unless,omitempty"`
+}
+
+// Condition matches decoded transaction fields. Versions are named as the
+// keys of the version table ("basic", "updateStructure", ...). Prefixes
+// match the recipient prefix, or the prefix field of structure
+// transactions. Transactions without a recipient never match Recipients,
+// transactions without a value have a value of zero.
+type Condition struct {
+	Versions   []string    `json:"versions,omitempty" yaml:"versions,omitempty"`
+	Senders    []string    `json:"senders,omitempty" yaml:"senders,omitempty"`
+	Recipients []string    `json:"recipients,omitempty" yaml:"recipients,omitempty"`
+	Prefixes   []string    `json:"prefixes,omitempty" yaml:"prefixes,omitempty"`
+	Value      *ValueRange `json:"value,omitempty" yaml:"value,omitempty"`
+	Time       *TimeWindow `json:"time,omitempty" yaml:"time,omitempty"`
+}
+
+// ValueRange bounds are inclusive, a nil bound is open.
+type ValueRange struct {
+	Min *amount.Amount `json:"min,omitempty" yaml:"min,omitempty"`
+	Max *amount.Amount `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// TimeWindow matches the evaluation time on Days ("mon".."sun", all days
+// when empty) from From up to, but not including, To ("15:04"). A window
+// with From after To spans midnight. Location defaults to UTC.
+type TimeWindow struct {
+	Days     []string `json:"days,omitempty" yaml:"days,omitempty"`
+	From     string   `json:"from,omitempty" yaml:"from,omitempty"`
+	To       string   `json:"to,omitempty" yaml:"to,omitempty"`
+	Location string   `json:"location,omitempty" yaml:"location,omitempty"`
+}
+
+// Decision is the outcome of an evaluation with the reasons for it.
+type Decision struct {
+	Allow   bool     `json:"allow"`
+	Reasons []string `json:"reasons"`
+}
+
+// Err returns nil for an allow decision and ErrDenied with the reasons
+// otherwise.
+func (d Decision) Err() error {
+	if d.Allow {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrDenied, strings.Join(d.Reasons, "; "))
+}
+
+// Engine is a compiled Policy, safe for concurrent use.
+type Engine struct {
+	allow bool
+	rules []rule
+}
+
+type rule struct {
+	reason string
+	deny   bool
+	when   *condition
+	unless *condition
+}
+
+type condition struct {
+	versions   map[uint8]struct{}
+	senders    map[string]struct{}
+	recipients map[string]struct{}
+	prefixes   map[string]struct{}
+	value      *ValueRange
+	time       *window
+}
+
+type window struct {
+	days     map[time.Weekday]struct{}
+	from, to time.Duration
+	loc      *time.Location
+}
+
+// Parse reads a JSON policy and compiles it.
+func Parse(data []byte) (*Engine, error) {
+	var p Policy
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("%w: trailing data", ErrInvalidPolicy)
+	}
+
+	return Compile(&p)
+}
+
+// ParseYAML reads a YAML policy and compiles it.
+func ParseYAML(data []byte) (*Engine, error) {
+	var p Policy
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+
+	return Compile(&p)
+}
+
+// Compile checks p and prepares it for evaluation.
+func Compile(p *Policy) (*Engine, error) {
+	e := &Engine{rules: make([]rule, len(p.Rules))}
+
+	switch p.Default {
+	case "", Allow:
+		e.allow = true
+	case Deny:
+	default:
+		return nil, fmt.Errorf("%w: unknown default %q", ErrInvalidPolicy, p.Default)
+	}
+
+	for i, r := range p.Rules {
+		c, err := compileRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %v", ErrInvalidPolicy, i, err)
+		}
+
+		if c.reason == "" {
+			c.reason = fmt.Sprintf("rule %d", i)
+		}
+
+		e.rules[i] = c
+	}
+
+	return e, nil
+}
+
+// Evaluate decides whether t may be signed at time now.
+func (e *Engine) Evaluate(t *transaction.Transaction, now time.Time) Decision {
+	var allowed, denied []string
+
+	for _, r := range e.rules {
+		if !r.when.match(t, now, true) || r.unless.match(t, now, false) {
+			continue
+		}
+
+		if r.deny {
+			denied = append(denied, r.reason)
+		} else {
+			allowed = append(allowed, r.reason)
+		}
+	}
+
+	switch {
+	case len(denied) > 0:
+		return Decision{Allow: false, Reasons: denied}
+	case len(allowed) > 0:
+		return Decision{Allow: true, Reasons: allowed}
+	default:
+		return Decision{Allow: e.allow, Reasons: []string{"default"}}
+	}
+}
+
+// Check is Evaluate reduced to an error, it returns ErrDenied with the
+// reasons when t may not be signed. Its signature matches
+// signer.TransactionPolicy.
+func (e *Engine) Check(t *transaction.Transaction, now time.Time) error {
+	return e.Evaluate(t, now).Err()
+}
+
+func compileRule(r Rule) (c rule, err error) {
+	switch r.Effect {
+	case Allow:
+	case Deny:
+		c.deny = true
+	default:
+		return c, fmt.Errorf("unknown effect %q", r.Effect)
+	}
+
+	c.reason = r.Reason
+	if c.reason == "" {
+		c.reason = r.Name
+	}
+
+	if c.when, err = compileCondition(r.When); err != nil {
+		return c, err
+	}
+
+	c.unless, err = compileCondition(r.Unless)
+
+	return c, err
+}
+
+func compileCondition(c *Condition) (*condition, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	// An empty condition matches everything, which is never what a
+	// misspelled field was meant to say.
+	if c.empty() {
+		return nil, errors.New("empty condition")
+	}
+
+	var (
+		cc  = &condition{value: c.Value}
+		err error
+	)
+
+	if len(c.Versions) > 0 {
+		cc.versions = make(map[uint8]struct{}, len(c.Versions))
+
+		for _, s := range c.Versions {
+			v, ok := versions[s]
+			if !ok {
+				return nil, fmt.Errorf("unknown version %q", s)
+			}
+
+			cc.versions[v] = struct{}{}
+		}
+	}
+
+	if cc.senders, err = addressSet(c.Senders); err != nil {
+		return nil, err
+	}
+
+	if cc.recipients, err = addressSet(c.Recipients); err != nil {
+		return nil, err
+	}
+
+	if len(c.Prefixes) > 0 {
+		cc.prefixes = make(map[string]struct{}, len(c.Prefixes))
+
+		for _, p := range c.Prefixes {
+			if _, err := util.ParsePrefix(p); err != nil {
+				return nil, fmt.Errorf("invalid prefix %q", p)
+			}
+
+			cc.prefixes[p] = struct{}{}
+		}
+	}
+
+	if v := c.Value; v != nil && v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+		return nil, fmt.Errorf("value min %s exceeds max %s", v.Min, v.Max)
+	}
+
+	if cc.time, err = compileWindow(c.Time); err != nil {
+		return nil, err
+	}
+
+	return cc, nil
+}
+
+func (c *Condition) empty() bool {
+	return len(c.Versions) == 0 && len(c.Senders) == 0 && len(c.Recipients) == 0 && len(c.Prefixes) == 0 &&
+		(c.Value == nil || c.Value.Min == nil && c.Value.Max == nil) &&
+		(c.Time == nil || len(c.Time.Days) == 0 && c.Time.From == "" && c.Time.To == "")
+}
+
+func addressSet(list []string) (map[string]struct{}, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	set := make(map[string]struct{}, len(list))
+
+	for _, s := range list {
+		a, err := address.ParseBech32(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q", s)
+		}
+
+		set[string(a.Bytes)] = struct{}{}
+	}
+
+	return set, nil
+}
+
+func compileWindow(tw *TimeWindow) (*window, error) {
+	if tw == nil {
+		return nil, nil
+	}
+
+	w := &window{loc: time.UTC, to: 24 * time.Hour}
+
+	if tw.Location != "" {
+		loc, err := time.LoadLocation(tw.Location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid location %q", tw.Location)
+		}
+
+		w.loc = loc
+	}
+
+	if len(tw.Days) > 0 {
+		w.days = make(map[time.Weekday]struct{}, len(tw.Days))
+
+		for _, s := range tw.Days {
+			d, ok := weekdays[strings.ToLower(s)]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %q", s)
+			}
+
+			w.days[d] = struct{}{}
+		}
+	}
+
+	var err error
+
+	if tw.From != "" {
+		if w.from, err = clock(tw.From); err != nil {
+			return nil, err
+		}
+	}
+
+	if tw.To != "" {
+		if w.to, err = clock(tw.To); err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+func clock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// match reports whether every set field of c matches, a nil c matches
+// as empty.
+func (c *condition) match(t *transaction.Transaction, now time.Time, empty bool) bool {
+	if c == nil {
+		return empty
+	}
+
+	ver := t.Version()
+
+	return c.matchVersion(ver) &&
+		matchSet(c.senders, string(t.Sender().Bytes)) &&
+		c.matchRecipient(t, ver) &&
+		c.matchPrefix(t, ver) &&
+		c.matchValue(t, ver) &&
+		c.time.match(now)
+}
+
+func (c *condition) matchVersion(ver uint8) bool {
+	if c.versions == nil {
+		return true
+	}
+
+	_, ok := c.versions[ver]
+
+	return ok
+}
+
+func (c *condition) matchRecipient(t *transaction.Transaction, ver uint8) bool {
+	if c.recipients == nil {
+		return true
+	}
+
+	if isStructure(ver) {
+		return false
+	}
+
+	return matchSet(c.recipients, string(t.Recipient().Bytes))
+}
+
+func (c *condition) matchPrefix(t *transaction.Transaction, ver uint8) bool {
+	if c.prefixes == nil {
+		return true
+	}
+
+	if isStructure(ver) {
+		return matchSet(c.prefixes, t.Prefix())
+	}
+
+	return matchSet(c.prefixes, t.Recipient().Prefix())
+}
+
+func (c *condition) matchValue(t *transaction.Transaction, ver uint8) bool {
+	if c.value == nil {
+		return true
+	}
+
+	var v amount.Amount
+	if ver == transaction.Genesis || ver == transaction.Basic {
+		v = t.Amount()
+	}
+
+	return (c.value.Min == nil || v >= *c.value.Min) && (c.value.Max == nil || v <= *c.value.Max)
+}
+
+func (w *window) match(now time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	now = now.In(w.loc)
+
+	if w.days != nil {
+		if _, ok := w.days[now.Weekday()]; !ok {
+			return false
+		}
+	}
+
+	d := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+
+	if w.from <= w.to {
+		return d >= w.from && d < w.to
+	}
+
+	return d >= w.from || d < w.to
+}
+
+func matchSet(set map[string]struct{}, s string) bool {
+	if set == nil {
+		return true
+	}
+
+	_, ok := set[s]
+
+	return ok
+}
+
+func isStructure(ver uint8) bool {
+	return ver == transaction.CreateSmartContract || ver == transaction.UpdateSmartContract
+}
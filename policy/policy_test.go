@@ -0,0 +1,245 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package policy_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/policy"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newAddress() *address.Address {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	return address.FromKey(key.NewPublicKey(pub))
+}
+
+func basic(to *address.Address, v amount.Amount) *transaction.Transaction {
+	return transaction.NewTransaction().
+		SetVersion(transaction.Basic).
+		SetSender(newAddress()).
+		SetRecipient(to).
+		SetAmount(v)
+}
+
+func updateStructure(prefix string) *transaction.Transaction {
+	return transaction.NewTransaction().
+		SetVersion(transaction.UpdateSmartContract).
+		SetSender(newAddress()).
+		SetPrefix(prefix).
+		SetProfitPercent(100)
+}
+
+func TestEvaluate(t *testing.T) {
+	friend := newAddress()
+
+	e, err := policy.Parse([]byte(fmt.Sprintf(`{
+		"rules": [
+			{"name": "basic only", "effect": "deny",
+			 "unless": {"versions": ["basic", "updateStructure"]}},
+			{"name": "value under 1000", "effect": "deny",
+			 "when": {"value": {"min": "1000.00"}}},
+			{"name": "allowlist", "effect": "deny",
+			 "when": {"versions": ["basic"]}, "unless": {"recipients": [%q]}},
+			{"name": "business hours", "effect": "deny", "reason": "structure update outside business hours",
+			 "when": {"versions": ["updateStructure"]},
+			 "unless": {"time": {"days": ["mon", "tue", "wed", "thu", "fri"], "from": "09:00", "to": "18:00"}}}
+		]
+	}`, friend.ToBech32())))
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	monday := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	sunday := time.Date(2020, 6, 7, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		tx      *transaction.Transaction
+		now     time.Time
+		allow   bool
+		reasons []string
+	}{
+		{"allowed", basic(friend, 99999), monday, true, []string{"default"}},
+		{"value", basic(friend, 100000), monday, false, []string{"value under 1000"}},
+		{"recipient", basic(newAddress(), 1), monday, false, []string{"allowlist"}},
+		{"both", basic(newAddress(), 100000), monday, false, []string{"value under 1000", "allowlist"}},
+		{"version", transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).SetPrefix("aaa"), monday, false, []string{"basic only"}},
+		{"structure", updateStructure("aaa"), monday, true, []string{"default"}},
+		{"weekend", updateStructure("aaa"), sunday, false, []string{"structure update outside business hours"}},
+		{"evening", updateStructure("aaa"), monday.Add(8 * time.Hour), false, []string{"structure update outside business hours"}},
+	}
+
+	for _, tt := range tests {
+		d := e.Evaluate(tt.tx, tt.now)
+
+		if d.Allow != tt.allow || fmt.Sprint(d.Reasons) != fmt.Sprint(tt.reasons) {
+			t.Fatalf("%s: Expected: %v %v, got: %v %v", tt.name, tt.allow, tt.reasons, d.Allow, d.Reasons)
+		}
+
+		if err := d.Err(); (err == nil) != tt.allow || (err != nil && !errors.Is(err, policy.ErrDenied)) {
+			t.Fatalf("%s: Expected: %v, got: %v", tt.name, policy.ErrDenied, err)
+		}
+	}
+}
+
+func TestDefaultDeny(t *testing.T) {
+	e, err := policy.Parse([]byte(`{
+		"default": "deny",
+		"rules": [
+			{"name": "small transfers", "effect": "allow", "when": {"versions": ["basic"], "value": {"max": "10.00"}}},
+			{"name": "own structure", "effect": "allow", "when": {"prefixes": ["aaa"]}},
+			{"name": "night", "effect": "allow", "when": {"time": {"from": "22:00", "to": "06:00"}}}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	noon := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2020, 6, 1, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		tx    *transaction.Transaction
+		now   time.Time
+		allow bool
+	}{
+		{"small", basic(newAddress(), 1000), noon, true},
+		{"large", basic(newAddress(), 1001), noon, false},
+		{"prefix", basic(newAddress().SetPrefix("aaa"), 1001), noon, true},
+		{"structure", updateStructure("aaa"), noon, true},
+		{"other structure", updateStructure("bbb"), noon, false},
+		{"night", updateStructure("bbb"), night, true},
+	}
+
+	for _, tt := range tests {
+		if d := e.Evaluate(tt.tx, tt.now); d.Allow != tt.allow {
+			t.Fatalf("%s: Expected: %v, got: %v %v", tt.name, tt.allow, d.Allow, d.Reasons)
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	friend := newAddress()
+
+	e, err := policy.ParseYAML([]byte(fmt.Sprintf(`
+default: deny
+rules:
+  - name: friend
+    effect: allow
+    when:
+      versions: [basic]
+      recipients: [%s]
+  - name: limit
+    effect: deny
+    when: {value: {min: "1000.00"}}
+`, friend.ToBech32())))
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		tx    *transaction.Transaction
+		allow bool
+	}{
+		{"allowed", basic(friend, 99999), true},
+		{"value", basic(friend, 100000), false},
+		{"recipient", basic(newAddress(), 1), false},
+	}
+
+	for _, tt := range tests {
+		if d := e.Evaluate(tt.tx, time.Now()); d.Allow != tt.allow {
+			t.Fatalf("%s: Expected: %v, got: %v %v", tt.name, tt.allow, d.Allow, d.Reasons)
+		}
+	}
+
+	errs := []string{
+		"rules: [",
+		"rules:\n  - effect: deny\n    unless: {recipent: [umi1]}\n",
+		"rules:\n  - effect: deny\n    unless: {}\n",
+		"rules:\n  - effect: deny\n    when: {value: {min: 1.001}}\n",
+		"",
+	}
+
+	for _, tt := range errs {
+		if _, err := policy.ParseYAML([]byte(tt)); !errors.Is(err, policy.ErrInvalidPolicy) {
+			t.Fatalf("%q: Expected: %v, got: %v", tt, policy.ErrInvalidPolicy, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		`{"rules": [`,
+		`{"default": "maybe"}`,
+		`{"rules": [{"effect": "block"}]}`,
+		`{"rules": [{"effect": "deny", "when": {"versions": ["transfer"]}}]}`,
+		`{"rules": [{"effect": "deny", "when": {"senders": ["umi1"]}}]}`,
+		`{"rules": [{"effect": "deny", "unless": {"prefixes": ["AAA"]}}]}`,
+		`{"rules": [{"effect": "deny", "when": {"value": {"min": "2.00", "max": "1.00"}}}]}`,
+		`{"rules": [{"effect": "deny", "when": {"value": {"min": "1.001"}}}]}`,
+		`{"rules": [{"effect": "deny", "when": {"time": {"days": ["someday"]}}}]}`,
+		`{"rules": [{"effect": "deny", "when": {"time": {"from": "25:00"}}}]}`,
+		`{"rules": [{"effect": "deny", "when": {"time": {"from": "09:00", "location": "Nowhere/Void"}}}]}`,
+		`{"rules": [{"effect": "deny", "unless": {"recipent": ["umi1"]}}]}`,
+		`{"rules": [{"effect": "deny", "unless": {}}]}`,
+		`{"rules": [{"effect": "deny", "when": {"value": {}}}]}`,
+		`{"rules": [{"effect": "deny", "when": {"time": {"location": "UTC"}}}]}`,
+		`{"rules": [{"name": "x", "efect": "deny"}]}`,
+		`{"rules": []} {}`,
+	}
+
+	for _, tt := range tests {
+		if _, err := policy.Parse([]byte(tt)); !errors.Is(err, policy.ErrInvalidPolicy) {
+			t.Fatalf("%s: Expected: %v, got: %v", tt, policy.ErrInvalidPolicy, err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	e, err := policy.Compile(&policy.Policy{
+		Rules: []policy.Rule{{
+			Name:   "no structures",
+			Effect: policy.Deny,
+			Unless: &policy.Condition{Versions: []string{"basic"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := e.Check(basic(newAddress(), 1), time.Now()); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := e.Check(updateStructure("aaa"), time.Now()); !errors.Is(err, policy.ErrDenied) {
+		t.Fatalf("Expected: %v, got: %v", policy.ErrDenied, err)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package qr
+
+import (
+	"strings"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/uri"
+)
+
+// EncodeAddress encodes the bech32 form of a in upper case, which bech32
+// allows and which fits the denser alphanumeric mode.
+func EncodeAddress(a *address.Address, lvl Level) (*Code, error) {
+	if err := a.Verify(); err != nil {
+		return nil, err
+	}
+
+	return Encode(strings.ToUpper(a.ToBech32()), lvl)
+}
+
+// EncodePaymentRequest encodes r as an umi: URI. Requests without
+// parameters are upper-cased like addresses.
+func EncodePaymentRequest(r *uri.PaymentRequest, lvl Level) (*Code, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := r.String()
+	if !strings.Contains(s, "?") {
+		s = strings.ToUpper(s)
+	}
+
+	return Encode(s, lvl)
+}
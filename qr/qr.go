@@ -0,0 +1,175 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package qr is a minimal QR code encoder supporting the byte and
+// alphanumeric modes and all error correction levels.
+package qr
+
+import (
+	"errors"
+	"strings"
+)
+
+type Level int
+
+const (
+	L Level = iota // ~7% of codewords can be restored
+	M              // ~15%
+	Q              // ~25%
+	H              // ~30%
+)
+
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+var (
+	ErrInvalidLevel = errors.New("qr: invalid error correction level")
+	ErrTooLong      = errors.New("qr: data too long")
+)
+
+type Code struct {
+	Version int
+	Level   Level
+	Mask    int
+	Size    int
+
+	modules  [][]bool
+	function [][]bool
+}
+
+// Black reports whether the module at column x and row y is dark.
+// Coordinates outside of the symbol are light.
+func (c *Code) Black(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// Encode picks the alphanumeric mode when every character of text
+// allows it and the byte mode otherwise, and uses the smallest version
+// that fits.
+func Encode(text string, lvl Level) (*Code, error) {
+	if lvl < L || lvl > H {
+		return nil, ErrInvalidLevel
+	}
+
+	alnum := isAlphanumeric(text)
+
+	for ver := 1; ver <= 40; ver++ {
+		bits := encodeSegment(text, alnum, ver)
+		capacity := dataCodewords(ver, lvl) * 8
+
+		if bits.len() > capacity {
+			continue
+		}
+
+		// Terminator, byte alignment and pad bytes.
+		bits.append(0, min(4, capacity-bits.len()))
+		bits.append(0, (8-bits.len()%8)%8)
+
+		for pad := 0xEC; bits.len() < capacity; pad ^= 0xEC ^ 0x11 {
+			bits.append(pad, 8)
+		}
+
+		return newCode(ver, lvl, interleave(bits.bytes(), ver, lvl)), nil
+	}
+
+	return nil, ErrTooLong
+}
+
+func isAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(alphanumeric, s[i]) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func encodeSegment(text string, alnum bool, ver int) *bitBuffer {
+	b := &bitBuffer{}
+
+	if alnum {
+		b.append(0x2, 4)
+		b.append(len(text), [3]int{9, 11, 13}[countIndex(ver)])
+
+		for i := 0; i+1 < len(text); i += 2 {
+			b.append(strings.IndexByte(alphanumeric, text[i])*45+strings.IndexByte(alphanumeric, text[i+1]), 11)
+		}
+
+		if len(text)%2 == 1 {
+			b.append(strings.IndexByte(alphanumeric, text[len(text)-1]), 6)
+		}
+
+		return b
+	}
+
+	b.append(0x4, 4)
+	b.append(len(text), [3]int{8, 16, 16}[countIndex(ver)])
+
+	for i := 0; i < len(text); i++ {
+		b.append(int(text[i]), 8)
+	}
+
+	return b
+}
+
+func countIndex(ver int) int {
+	switch {
+	case ver <= 9:
+		return 0
+	case ver <= 26:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		b.bits = append(b.bits, (v>>uint(i))&1 == 1)
+	}
+}
+
+func (b *bitBuffer) bytes() []byte {
+	res := make([]byte, len(b.bits)/8)
+
+	for i, v := range b.bits {
+		if v {
+			res[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+
+	return res
+}
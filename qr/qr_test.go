@@ -0,0 +1,432 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package qr_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/qr"
+	"github.com/umi-top/umi-core/uri"
+)
+
+// reference holds the symbol parameters of ISO/IEC 18004 for the versions
+// used below, independently of the encoder tables.
+type reference struct {
+	align  []int
+	blocks [][2]int // count, data codewords
+	ecc    int
+}
+
+var references = map[[2]int]reference{
+	{1, int(qr.Q)}:  {nil, [][2]int{{1, 13}}, 13},
+	{4, int(qr.M)}:  {[]int{6, 26}, [][2]int{{2, 32}}, 18},
+	{5, int(qr.L)}:  {[]int{6, 30}, [][2]int{{1, 108}}, 26},
+	{6, int(qr.L)}:  {[]int{6, 34}, [][2]int{{2, 68}}, 18},
+	{7, int(qr.M)}:  {[]int{6, 22, 38}, [][2]int{{4, 31}}, 18},
+	{10, int(qr.H)}: {[]int{6, 28, 50}, [][2]int{{6, 15}, {2, 16}}, 28},
+}
+
+func TestHelloWorld(t *testing.T) {
+	c, err := qr.Encode("HELLO WORLD", qr.Q)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	exp := []byte{
+		32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236,
+		168, 72, 22, 82, 217, 54, 156, 0, 46, 15, 180, 122, 16,
+	}
+
+	raw, text := decode(t, c)
+
+	if !bytes.Equal(exp, raw) {
+		t.Fatalf("Expected: %v, got: %v", exp, raw)
+	}
+
+	if text != "HELLO WORLD" {
+		t.Fatalf("Expected: HELLO WORLD, got: %s", text)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	cases := []struct {
+		desc string
+		text string
+		lvl  qr.Level
+		ver  int
+	}{
+		{"alphanumeric", strings.Repeat("UMI1 $%*+-./:", 14)[:170], qr.M, 7},
+		{"byte", strings.Repeat("umi:payment?", 10)[:110], qr.H, 10},
+		{"utf-8", "оплата " + strings.Repeat("x", 80), qr.L, 5},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			c, err := qr.Encode(tc.text, tc.lvl)
+			if err != nil {
+				t.Fatalf("Expected: nil, got: %v", err)
+			}
+
+			if c.Version != tc.ver {
+				t.Fatalf("Expected: %d, got: %d", tc.ver, c.Version)
+			}
+
+			if _, text := decode(t, c); text != tc.text {
+				t.Fatalf("Expected: %s, got: %s", tc.text, text)
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := qr.Encode(strings.Repeat("x", 1300), qr.H); !errors.Is(err, qr.ErrTooLong) {
+		t.Fatalf("Expected: %v, got: %v", qr.ErrTooLong, err)
+	}
+
+	c, err := qr.Encode(strings.Repeat("x", 2953), qr.L)
+	if err != nil || c.Version != 40 {
+		t.Fatalf("Expected: version 40, got: %v", err)
+	}
+}
+
+func TestEncodeAddress(t *testing.T) {
+	adr := address.FromBech32("umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhg")
+
+	c, err := qr.EncodeAddress(adr, qr.M)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if _, text := decode(t, c); text != strings.ToUpper(adr.ToBech32()) {
+		t.Fatalf("Expected: %s, got: %s", strings.ToUpper(adr.ToBech32()), text)
+	}
+
+	r := &uri.PaymentRequest{Address: adr, Amount: 100, Message: "Invoice 7, thanks!"}
+
+	c, err = qr.EncodePaymentRequest(r, qr.L)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if _, text := decode(t, c); text != r.String() {
+		t.Fatalf("Expected: %s, got: %s", r.String(), text)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	c, _ := qr.Encode("HELLO WORLD", qr.Q)
+
+	b, err := c.PNG(3)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if n := (c.Size + 2*qr.QuietZone) * 3; img.Bounds().Dx() != n {
+		t.Fatalf("Expected: %d, got: %d", n, img.Bounds().Dx())
+	}
+
+	for y := -qr.QuietZone; y < c.Size+qr.QuietZone; y++ {
+		for x := -qr.QuietZone; x < c.Size+qr.QuietZone; x++ {
+			r, _, _, _ := img.At((x+qr.QuietZone)*3+1, (y+qr.QuietZone)*3+1).RGBA()
+			if (r == 0) != c.Black(x, y) {
+				t.Fatalf("Unexpected pixel at %d,%d", x, y)
+			}
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	c, _ := qr.Encode("HELLO WORLD", qr.Q)
+	n := c.Size + 2*qr.QuietZone
+
+	lines := strings.Split(strings.TrimSuffix(c.ASCII(), "\n"), "\n")
+	if len(lines) != n || len(lines[qr.QuietZone]) != 2*n || !strings.HasPrefix(lines[qr.QuietZone], "        ##############") {
+		t.Fatalf("Unexpected ASCII rendering:\n%s", c.ASCII())
+	}
+
+	lines = strings.Split(strings.TrimSuffix(c.Terminal(), "\n"), "\n")
+	if len(lines) != (n+1)/2 || len([]rune(lines[0])) != n {
+		t.Fatalf("Unexpected terminal rendering:\n%s", c.Terminal())
+	}
+}
+
+// decode reads a symbol back following ISO/IEC 18004: it checks the format
+// and version information, unmasks the data modules, verifies the
+// Reed-Solomon codewords of every block and parses the segment. It returns
+// the interleaved codewords and the decoded text.
+func decode(t *testing.T, c *qr.Code) ([]byte, string) {
+	t.Helper()
+
+	n := c.Size
+	ver := (n - 17) / 4
+
+	ref, ok := references[[2]int{ver, int(c.Level)}]
+	if !ok {
+		t.Fatalf("No reference for version %d level %d", ver, c.Level)
+	}
+
+	// Format information, both copies.
+	var f1, f2 int
+
+	for i := 0; i < 15; i++ {
+		var x, y int
+
+		switch {
+		case i <= 5:
+			x, y = 8, i
+		case i == 6:
+			x, y = 8, 7
+		case i == 7:
+			x, y = 8, 8
+		case i == 8:
+			x, y = 7, 8
+		default:
+			x, y = 14-i, 8
+		}
+
+		if c.Black(x, y) {
+			f1 |= 1 << uint(i)
+		}
+
+		if i < 8 && c.Black(n-1-i, 8) || i >= 8 && c.Black(8, n-15+i) {
+			f2 |= 1 << uint(i)
+		}
+	}
+
+	lvl := map[int]qr.Level{1: qr.L, 0: qr.M, 3: qr.Q, 2: qr.H}[f1>>13^0x5412>>13]
+	mask := (f1 ^ 0x5412) >> 10 & 7
+
+	if f1 != f2 || f1 != format(int(lvl), mask) || lvl != c.Level || mask != c.Mask {
+		t.Fatalf("Invalid format information %015b %015b", f1, f2)
+	}
+
+	if !c.Black(8, n-8) {
+		t.Fatal("Missing dark module")
+	}
+
+	if ver >= 7 {
+		exp := map[int]int{7: 0x07C94, 10: 0x0A4D3}[ver]
+
+		for i := 0; i < 18; i++ {
+			a, b := n-11+i%3, i/3
+			if c.Black(a, b) != (exp>>uint(i)&1 == 1) || c.Black(b, a) != (exp>>uint(i)&1 == 1) {
+				t.Fatalf("Invalid version information at bit %d", i)
+			}
+		}
+	}
+
+	function := func(x, y int) bool {
+		switch {
+		case x < 9 && y < 9, x >= n-8 && y < 9, x < 9 && y >= n-8:
+			return true
+		case x == 6 || y == 6:
+			return true
+		case ver >= 7 && (x >= n-11 && x < n-8 && y < 6 || y >= n-11 && y < n-8 && x < 6):
+			return true
+		}
+
+		for _, ax := range ref.align {
+			for _, ay := range ref.align {
+				if ax == 6 && ay == 6 || ax == 6 && ay == n-7 || ax == n-7 && ay == 6 {
+					continue
+				}
+
+				if x >= ax-2 && x <= ax+2 && y >= ay-2 && y <= ay+2 {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	masks := []func(x, y int) bool{
+		func(x, y int) bool { return (y+x)%2 == 0 },
+		func(x, y int) bool { return y%2 == 0 },
+		func(x, y int) bool { return x%3 == 0 },
+		func(x, y int) bool { return (y+x)%3 == 0 },
+		func(x, y int) bool { return (y/2+x/3)%2 == 0 },
+		func(x, y int) bool { return (y*x)%2+(y*x)%3 == 0 },
+		func(x, y int) bool { return ((y*x)%2+(y*x)%3)%2 == 0 },
+		func(x, y int) bool { return ((y+x)%2+(y*x)%3)%2 == 0 },
+	}
+
+	var bits []bool
+
+	for right := n - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+
+		for v := 0; v < n; v++ {
+			y := v
+			if (right+1)&2 == 0 {
+				y = n - 1 - v
+			}
+
+			for x := right; x >= right-1; x-- {
+				if !function(x, y) {
+					bits = append(bits, c.Black(x, y) != masks[mask](x, y))
+				}
+			}
+		}
+	}
+
+	total := 0
+	for _, b := range ref.blocks {
+		total += b[0] * (b[1] + ref.ecc)
+	}
+
+	if len(bits)/8 != total {
+		t.Fatalf("Expected %d codewords, got %d", total, len(bits)/8)
+	}
+
+	raw := make([]byte, total)
+
+	for i := range raw {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				raw[i] |= 0x80 >> uint(j)
+			}
+		}
+	}
+
+	// De-interleave data and error correction codewords.
+	var blocks [][]byte
+
+	for _, b := range ref.blocks {
+		for i := 0; i < b[0]; i++ {
+			blocks = append(blocks, make([]byte, 0, b[1]+ref.ecc))
+		}
+	}
+
+	k := 0
+
+	for i := 0; k < total-len(blocks)*ref.ecc; i++ {
+		for j := range blocks {
+			if i < cap(blocks[j])-ref.ecc {
+				blocks[j] = append(blocks[j], raw[k])
+				k++
+			}
+		}
+	}
+
+	for i := 0; i < ref.ecc; i++ {
+		for j := range blocks {
+			blocks[j] = append(blocks[j], raw[k])
+			k++
+		}
+	}
+
+	var data []byte
+
+	for _, b := range blocks {
+		for i, a := 0, byte(1); i < ref.ecc; i, a = i+1, mul(a, 2) {
+			s := byte(0)
+			for _, v := range b {
+				s = mul(s, a) ^ v
+			}
+
+			if s != 0 {
+				t.Fatalf("Non-zero syndrome %d", i)
+			}
+		}
+
+		data = append(data, b[:len(b)-ref.ecc]...)
+	}
+
+	return raw, parse(t, data, ver)
+}
+
+func parse(t *testing.T, data []byte, ver int) string {
+	pos := 0
+	read := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v = v<<1 | int(data[pos/8]>>uint(7-pos%8)&1)
+			pos++
+		}
+
+		return v
+	}
+
+	const chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+	var b []byte
+
+	switch mode := read(4); mode {
+	case 2:
+		n := read(map[bool]int{true: 9, false: 11}[ver <= 9])
+		for ; n >= 2; n -= 2 {
+			v := read(11)
+			b = append(b, chars[v/45], chars[v%45])
+		}
+
+		if n == 1 {
+			b = append(b, chars[read(6)])
+		}
+	case 4:
+		n := read(map[bool]int{true: 8, false: 16}[ver <= 9])
+		for i := 0; i < n; i++ {
+			b = append(b, byte(read(8)))
+		}
+	default:
+		t.Fatalf("Unexpected mode %d", mode)
+	}
+
+	if pos+4 <= len(data)*8 && read(4) != 0 {
+		t.Fatal("Missing terminator")
+	}
+
+	return string(b)
+}
+
+func format(lvl, mask int) int {
+	data := map[int]int{0: 1, 1: 0, 2: 3, 3: 2}[lvl]<<3 | mask
+	rem := data
+
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func mul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>uint(i)&1) * int(x)
+	}
+
+	return byte(z)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package qr
+
+// gfMul multiplies in GF(256) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	z := 0
+
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+
+	return byte(z)
+}
+
+// rsDivisor returns the generator polynomial of the given degree without
+// its leading term, highest power first.
+func rsDivisor(degree int) []byte {
+	res := make([]byte, degree)
+	res[degree-1] = 1
+	root := byte(1)
+
+	for i := 0; i < degree; i++ {
+		for j := range res {
+			res[j] = gfMul(res[j], root)
+
+			if j+1 < len(res) {
+				res[j] ^= res[j+1]
+			}
+		}
+
+		root = gfMul(root, 0x02)
+	}
+
+	return res
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	res := make([]byte, len(divisor))
+
+	for _, b := range data {
+		factor := b ^ res[0]
+		copy(res, res[1:])
+		res[len(res)-1] = 0
+
+		for i, d := range divisor {
+			res[i] ^= gfMul(d, factor)
+		}
+	}
+
+	return res
+}
+
+// interleave splits data into blocks, appends error correction codewords
+// to each block and interleaves the result.
+func interleave(data []byte, ver int, lvl Level) []byte {
+	blocks, ecc := numBlocks[lvl][ver], eccPerBlock[lvl][ver]
+	raw := rawModules(ver) / 8
+	short := blocks - raw%blocks
+	shortLen := raw / blocks
+	div := rsDivisor(ecc)
+
+	bs := make([][]byte, blocks)
+
+	for i, k := 0, 0; i < blocks; i++ {
+		n := shortLen - ecc
+		if i >= short {
+			n++
+		}
+
+		b := make([]byte, 0, shortLen+1)
+		b = append(b, data[k:k+n]...)
+		k += n
+
+		r := rsRemainder(b, div)
+
+		if i < short {
+			b = append(b, 0)
+		}
+
+		bs[i] = append(b, r...)
+	}
+
+	res := make([]byte, 0, raw)
+
+	for i := range bs[0] {
+		for j, b := range bs {
+			if i != shortLen-ecc || j >= short {
+				res = append(res, b[i])
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package shamir
+
+// Arithmetic in GF(2^8) with the AES polynomial x^8 + x^4 + x^3 + x + 1.
+// Share bytes are secret, so multiplication runs without table lookups or
+// branches on its operands.
+
+func gfMul(a, b byte) byte {
+	var r byte
+
+	for i := 0; i < 8; i++ {
+		r ^= a & -(b & 1)
+		b >>= 1
+		a = a<<1 ^ 0x1b&-(a>>7)
+	}
+
+	return r
+}
+
+// gfInv returns a^254, the inverse of a non-zero a.
+func gfInv(a byte) byte {
+	a2 := gfMul(a, a)
+	a4 := gfMul(a2, a2)
+	a8 := gfMul(a4, a4)
+	a16 := gfMul(a8, a8)
+	a32 := gfMul(a16, a16)
+	a64 := gfMul(a32, a32)
+	a128 := gfMul(a64, a64)
+
+	return gfMul(gfMul(gfMul(a128, a64), gfMul(a32, a16)), gfMul(gfMul(a8, a4), a2))
+}
+
+// gfDiv panics on division by zero, callers ensure distinct x coordinates.
+// Only b is checked, it is a public difference of x coordinates.
+func gfDiv(a, b byte) byte {
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+
+	return gfMul(a, gfInv(b))
+}
+
+// interpolate evaluates at x the polynomial of lowest degree through the
+// points (xs[i], ys[i]).
+func interpolate(xs, ys []byte, x byte) byte {
+	var r byte
+
+	for i := range xs {
+		l := byte(1)
+
+		for j := range xs {
+			if i != j {
+				l = gfMul(l, gfDiv(x^xs[j], xs[i]^xs[j]))
+			}
+		}
+
+		r ^= gfMul(ys[i], l)
+	}
+
+	return r
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package shamir splits secrets, such as secret key seeds, into shares
+// with Shamir's scheme over GF(256).
+//
+// Any Threshold shares recover the secret, fewer reveal nothing about it.
+// Shares of one split carry the same set identifier; for keys it is derived
+// from the public key, so a recovered key can be checked against it.
+package shamir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/util/bech32"
+)
+
+// HRP is the human-readable part of encoded shares.
+const HRP = "umishare"
+
+var (
+	ErrInvalidParams      = errors.New("shamir: invalid threshold or number of shares")
+	ErrInvalidShare       = errors.New("shamir: invalid share")
+	ErrTooFewShares       = errors.New("shamir: not enough shares")
+	ErrInconsistentShares = errors.New("shamir: inconsistent shares")
+	ErrKeyMismatch        = errors.New("shamir: recovered key does not match")
+)
+
+type Share struct {
+	Set       uint32
+	Threshold uint8
+	Index     uint8
+	Value     []byte
+}
+
+// Split shares secret between n holders, any threshold of which can
+// recover it. The threshold is at least 2.
+func Split(secret []byte, threshold, n int, rand io.Reader) ([]Share, error) {
+	var set [4]byte
+	if _, err := io.ReadFull(rand, set[:]); err != nil {
+		return nil, err
+	}
+
+	return split(secret, binary.BigEndian.Uint32(set[:]), threshold, n, rand)
+}
+
+// SplitKey shares the seed of k. The set identifier is a fingerprint of
+// its public key.
+func SplitKey(k *key.SecretKey, threshold, n int, rand io.Reader) ([]Share, error) {
+	return split(k.Seed(), fingerprint(k.PublicKey()), threshold, n, rand)
+}
+
+func split(secret []byte, set uint32, threshold, n int, rand io.Reader) ([]Share, error) {
+	// A threshold of one would put the secret itself in every share.
+	if threshold < 2 || threshold > n || n > 255 || len(secret) == 0 {
+		return nil, ErrInvalidParams
+	}
+
+	// coef[j][i] is the coefficient of x^j for byte i, coef[0] is the secret.
+	coef := make([][]byte, threshold)
+	coef[0] = secret
+
+	for j := 1; j < threshold; j++ {
+		coef[j] = make([]byte, len(secret))
+		if _, err := io.ReadFull(rand, coef[j]); err != nil {
+			return nil, err
+		}
+	}
+
+	shares := make([]Share, n)
+
+	for s := range shares {
+		x := byte(s + 1)
+		v := make([]byte, len(secret))
+
+		for i := range v {
+			for j := threshold - 1; j >= 0; j-- {
+				v[i] = gfMul(v[i], x) ^ coef[j][i]
+			}
+		}
+
+		shares[s] = Share{Set: set, Threshold: uint8(threshold), Index: x, Value: v}
+	}
+
+	return shares, nil
+}
+
+// Recover reconstructs the secret. Shares beyond the threshold must lie on
+// the same polynomial, otherwise ErrInconsistentShares is returned.
+func Recover(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrTooFewShares
+	}
+
+	first := shares[0]
+	seen := make(map[uint8]struct{}, len(shares))
+
+	for _, s := range shares {
+		if s.Index == 0 || s.Threshold < 2 || len(s.Value) == 0 {
+			return nil, ErrInvalidShare
+		}
+
+		if s.Set != first.Set || s.Threshold != first.Threshold || len(s.Value) != len(first.Value) {
+			return nil, fmt.Errorf("%w: shares belong to different sets", ErrInconsistentShares)
+		}
+
+		if _, ok := seen[s.Index]; ok {
+			return nil, fmt.Errorf("%w: duplicate index %d", ErrInvalidShare, s.Index)
+		}
+
+		seen[s.Index] = struct{}{}
+	}
+
+	k := int(first.Threshold)
+	if len(shares) < k {
+		return nil, ErrTooFewShares
+	}
+
+	xs, ys := make([]byte, k), make([]byte, k)
+	for i := 0; i < k; i++ {
+		xs[i] = shares[i].Index
+	}
+
+	secret := make([]byte, len(first.Value))
+
+	for b := range secret {
+		for i := 0; i < k; i++ {
+			ys[i] = shares[i].Value[b]
+		}
+
+		secret[b] = interpolate(xs, ys, 0)
+
+		for _, s := range shares[k:] {
+			if interpolate(xs, ys, s.Index) != s.Value[b] {
+				return nil, fmt.Errorf("%w: share %d", ErrInconsistentShares, s.Index)
+			}
+		}
+	}
+
+	return secret, nil
+}
+
+// RecoverKey reconstructs a key split with SplitKey and checks it against
+// the set identifier and, unless it is nil, against the expected address.
+func RecoverKey(shares []Share, expected *address.Address) (*key.SecretKey, error) {
+	seed, err := Recover(shares)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seed) != key.SeedLength {
+		return nil, ErrKeyMismatch
+	}
+
+	k := key.NewSecretKeyFromSeed(seed)
+
+	if fingerprint(k.PublicKey()) != shares[0].Set {
+		return nil, ErrKeyMismatch
+	}
+
+	if expected != nil && !bytes.Equal(address.FromKey(k).PublicKey().ToBytes(), expected.PublicKey().ToBytes()) {
+		return nil, ErrKeyMismatch
+	}
+
+	return k, nil
+}
+
+func fingerprint(p *key.PublicKey) uint32 {
+	h := sha256.Sum256(p.ToBytes())
+	return binary.BigEndian.Uint32(h[0:4])
+}
+
+// String encodes s as bech32m: set, threshold, index and value. Values
+// longer than 39 bytes do not fit and give an empty string.
+func (s Share) String() string {
+	b := make([]byte, 6, 6+len(s.Value))
+	binary.BigEndian.PutUint32(b[0:4], s.Set)
+	b[4], b[5] = s.Threshold, s.Index
+	b = append(b, s.Value...)
+
+	str, err := bech32.EncodeBytes(HRP, b, bech32.Bech32m)
+	if err != nil {
+		return ""
+	}
+
+	return str
+}
+
+// ParseShare decodes a share, the bech32m checksum detects typos.
+func ParseShare(str string) (Share, error) {
+	hrp, b, v, err := bech32.DecodeBytes(str)
+	if err != nil {
+		return Share{}, fmt.Errorf("%w: %v", ErrInvalidShare, err)
+	}
+
+	if hrp != HRP || v != bech32.Bech32m || len(b) < 7 || b[4] < 2 || b[5] == 0 {
+		return Share{}, ErrInvalidShare
+	}
+
+	return Share{
+		Set:       binary.BigEndian.Uint32(b[0:4]),
+		Threshold: b[4],
+		Index:     b[5],
+		Value:     append([]byte(nil), b[6:]...),
+	}, nil
+}
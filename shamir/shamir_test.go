@@ -0,0 +1,163 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package shamir_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/shamir"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func TestSplitRecover(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	shares, err := shamir.Split(secret, 3, 5, rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	// every subset of three shares
+	for a := 0; a < 5; a++ {
+		for b := a + 1; b < 5; b++ {
+			for c := b + 1; c < 5; c++ {
+				act, err := shamir.Recover([]shamir.Share{shares[c], shares[a], shares[b]})
+				if err != nil || !bytes.Equal(act, secret) {
+					t.Fatalf("%d%d%d: Expected: %s, got: %s, %v", a, b, c, secret, act, err)
+				}
+			}
+		}
+	}
+
+	if act, err := shamir.Recover(shares); err != nil || !bytes.Equal(act, secret) {
+		t.Fatalf("Expected: %s, got: %s, %v", secret, act, err)
+	}
+
+	if _, err := shamir.Recover(shares[:2]); !errors.Is(err, shamir.ErrTooFewShares) {
+		t.Fatalf("Expected: %v, got: %v", shamir.ErrTooFewShares, err)
+	}
+
+	if _, err := shamir.Recover([]shamir.Share{shares[0], shares[0], shares[1]}); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Fatalf("Expected: %v, got: %v", shamir.ErrInvalidShare, err)
+	}
+
+	single := shares[0]
+	single.Threshold = 1
+
+	if _, err := shamir.Recover([]shamir.Share{single}); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Fatalf("Expected: %v, got: %v", shamir.ErrInvalidShare, err)
+	}
+
+	for _, c := range []struct{ k, n int }{{0, 3}, {1, 3}, {4, 3}, {2, 256}} {
+		if _, err := shamir.Split(secret, c.k, c.n, rand.Reader); !errors.Is(err, shamir.ErrInvalidParams) {
+			t.Fatalf("Expected: %v, got: %v", shamir.ErrInvalidParams, err)
+		}
+	}
+}
+
+func TestInconsistentShares(t *testing.T) {
+	shares, _ := shamir.Split([]byte("secret"), 2, 3, rand.Reader)
+	other, _ := shamir.Split([]byte("secret"), 2, 3, rand.Reader)
+
+	bad := shares[2]
+	bad.Value = append([]byte{}, bad.Value...)
+	bad.Value[0] ^= 1
+
+	if _, err := shamir.Recover([]shamir.Share{shares[0], shares[1], bad}); !errors.Is(err, shamir.ErrInconsistentShares) {
+		t.Fatalf("Expected: %v, got: %v", shamir.ErrInconsistentShares, err)
+	}
+
+	if _, err := shamir.Recover([]shamir.Share{shares[0], other[1]}); !errors.Is(err, shamir.ErrInconsistentShares) {
+		t.Fatalf("Expected: %v, got: %v", shamir.ErrInconsistentShares, err)
+	}
+}
+
+func TestSplitKey(t *testing.T) {
+	k := newKey()
+	adr := address.FromKey(k)
+
+	shares, err := shamir.SplitKey(k, 2, 3, rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	act, err := shamir.RecoverKey(shares[1:], adr)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if !bytes.Equal(act.ToBytes(), k.ToBytes()) {
+		t.Fatalf("Expected: %x, got: %x", k.ToBytes(), act.ToBytes())
+	}
+
+	if _, err := shamir.RecoverKey(shares[:2], address.FromKey(newKey())); !errors.Is(err, shamir.ErrKeyMismatch) {
+		t.Fatalf("Expected: %v, got: %v", shamir.ErrKeyMismatch, err)
+	}
+
+	// With exactly threshold shares a corrupted value is caught by the
+	// public key fingerprint.
+	bad := shares[0]
+	bad.Value = append([]byte{}, bad.Value...)
+	bad.Value[5] ^= 1
+
+	if _, err := shamir.RecoverKey([]shamir.Share{bad, shares[1]}, nil); !errors.Is(err, shamir.ErrKeyMismatch) {
+		t.Fatalf("Expected: %v, got: %v", shamir.ErrKeyMismatch, err)
+	}
+}
+
+func TestShareString(t *testing.T) {
+	shares, _ := shamir.SplitKey(newKey(), 2, 3, rand.Reader)
+	str := shares[2].String()
+
+	if str[:9] != "umishare1" {
+		t.Fatalf("Expected: umishare1 prefix, got: %s", str)
+	}
+
+	s, err := shamir.ParseShare(str)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if s.Set != shares[2].Set || s.Threshold != 2 || s.Index != 3 || !bytes.Equal(s.Value, shares[2].Value) {
+		t.Fatalf("Expected: %+v, got: %+v", shares[2], s)
+	}
+
+	typo := []byte(str)
+	if typo[20] == 'q' {
+		typo[20] = 'p'
+	} else {
+		typo[20] = 'q'
+	}
+
+	if _, err := shamir.ParseShare(string(typo)); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Fatalf("Expected: %v, got: %v", shamir.ErrInvalidShare, err)
+	}
+}
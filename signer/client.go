@@ -0,0 +1,182 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package signer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/umi-top/umi-core/key"
+)
+
+var ErrInvalidResponse = errors.New("signer: invalid response")
+
+// Client talks to a Server.
+type Client struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// NewClient returns a client for the server at url, a nil c means
+// http.DefaultClient.
+func NewClient(url string, c *http.Client) *Client {
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	return &Client{url: strings.TrimSuffix(url, "/"), http: c}
+}
+
+// SetToken sets the bearer token sent with every request.
+func (c *Client) SetToken(token string) *Client {
+	c.token = token
+
+	return c
+}
+
+// Keys lists the public keys held by the server.
+func (c *Client) Keys() ([]*key.PublicKey, error) {
+	var res struct {
+		Keys []jsonKey `json:"keys"`
+	}
+
+	if err := c.do(http.MethodGet, "/v1/keys", nil, &res); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*key.PublicKey, len(res.Keys))
+
+	for i, k := range res.Keys {
+		b, err := hex.DecodeString(k.PublicKey)
+		if err != nil || len(b) != 32 {
+			return nil, ErrInvalidResponse
+		}
+
+		keys[i] = key.NewPublicKey(b)
+	}
+
+	return keys, nil
+}
+
+// Signer returns a key.Signer for the server key pub. The key is not
+// checked until the first signature.
+func (c *Client) Signer(pub *key.PublicKey) key.Signer {
+	return &remoteSigner{client: c, pub: pub}
+}
+
+func (c *Client) sign(pub *key.PublicKey, msg []byte) ([]byte, error) {
+	req := signRequest{
+		PublicKey: hex.EncodeToString(pub.ToBytes()),
+		Data:      base64.StdEncoding.EncodeToString(msg),
+	}
+
+	var res signResponse
+
+	if err := c.do(http.MethodPost, "/v1/sign", req, &res); err != nil {
+		return nil, err
+	}
+
+	sig, err := hex.DecodeString(res.Signature)
+	if err != nil || !pub.VerifySignature(sig, msg) {
+		return nil, fmt.Errorf("%w: signature does not verify", ErrInvalidResponse)
+	}
+
+	return sig, nil
+}
+
+func (c *Client) do(method, path string, in, out interface{}) error {
+	var body bytes.Buffer
+
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.url+path, &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var e signResponse
+		_ = json.NewDecoder(res.Body).Decode(&e)
+
+		return fmt.Errorf("%w (%d %s)", statusError(res.StatusCode), res.StatusCode, e.Error)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+
+	return nil
+}
+
+// statusError maps a status code back to the server error, so that callers
+// can use errors.Is on both sides of the protocol.
+func statusError(code int) error {
+	switch code {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusForbidden:
+		return ErrDenied
+	case http.StatusNotFound:
+		return ErrUnknownKey
+	case http.StatusBadRequest:
+		return ErrInvalidRequest
+	default:
+		return ErrInvalidResponse
+	}
+}
+
+type remoteSigner struct {
+	client *Client
+	pub    *key.PublicKey
+}
+
+func (s *remoteSigner) PublicKey() *key.PublicKey {
+	return s.pub
+}
+
+func (s *remoteSigner) Sign(msg []byte) ([]byte, error) {
+	return s.client.sign(s.pub, msg)
+}
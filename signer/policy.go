@@ -0,0 +1,197 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+var (
+	ErrDenied      = errors.New("signer: denied by policy")
+	ErrRateLimited = errors.New("signer: rate limit exceeded")
+)
+
+// Request is a decoded signing request. Exactly one of Transaction and
+// Block is set, the signature part of both is empty.
+type Request struct {
+	Key         *key.PublicKey
+	Transaction *transaction.Transaction
+	Block       *block.Block
+	Remote      string
+	Time        time.Time
+}
+
+// Policy decides whether a request may be signed.
+type Policy interface {
+	Check(r *Request) error
+}
+
+// Releaser is implemented by policies that keep state for allowed
+// requests. Release undoes a Check that allowed r, it is called when r is
+// not signed after all.
+type Releaser interface {
+	Release(r *Request)
+}
+
+type PolicyFunc func(r *Request) error
+
+func (f PolicyFunc) Check(r *Request) error {
+	return f(r)
+}
+
+// All requires every policy to allow the request. When one denies it, the
+// policies that allowed it before are released, so their order does not
+// matter.
+func All(policies ...Policy) Policy {
+	return all(policies)
+}
+
+type all []Policy
+
+func (a all) Check(r *Request) error {
+	for i, p := range a {
+		if err := p.Check(r); err != nil {
+			a[:i].Release(r)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a all) Release(r *Request) {
+	for _, p := range a {
+		if rl, ok := p.(Releaser); ok {
+			rl.Release(r)
+		}
+	}
+}
+
+// TransactionPolicy applies check to the transaction of a request, block
+// headers are always allowed. An error from check is reported as
+// ErrDenied. It adapts evaluators such as policy.Engine.Check.
+func TransactionPolicy(check func(t *transaction.Transaction, now time.Time) error) Policy {
+	return PolicyFunc(func(r *Request) error {
+		if r.Transaction == nil {
+			return nil
+		}
+
+		if err := check(r.Transaction, r.Time); err != nil {
+			return fmt.Errorf("%w: %v", ErrDenied, err)
+		}
+
+		return nil
+	})
+}
+
+// AllowRecipients restricts the recipient of transactions that have one.
+func AllowRecipients(allowed ...*address.Address) Policy {
+	set := make(map[string]struct{}, len(allowed))
+	for _, a := range allowed {
+		set[string(a.Bytes)] = struct{}{}
+	}
+
+	return PolicyFunc(func(r *Request) error {
+		t := r.Transaction
+		if t == nil || t.Version() == transaction.CreateSmartContract || t.Version() == transaction.UpdateSmartContract {
+			return nil
+		}
+
+		if _, ok := set[string(t.Recipient().Bytes)]; !ok {
+			return fmt.Errorf("%w: recipient %s is not allowed", ErrDenied, t.Recipient().ToBech32())
+		}
+
+		return nil
+	})
+}
+
+// MaxValue limits the value of Basic transactions, the only transfers the
+// server signs.
+func MaxValue(max amount.Amount) Policy {
+	return PolicyFunc(func(r *Request) error {
+		if r.Transaction != nil && r.Transaction.Version() == transaction.Basic && r.Transaction.Amount() > max {
+			return fmt.Errorf("%w: value %s exceeds %s", ErrDenied, r.Transaction.Amount(), max)
+		}
+
+		return nil
+	})
+}
+
+// RateLimit allows at most n signatures per key within any window of
+// length per. An allowed request takes a slot until it is released, so
+// requests that are denied by another policy or fail to sign are not
+// counted.
+func RateLimit(n int, per time.Duration) Policy {
+	return &rateLimit{n: n, per: per, seen: make(map[string][]time.Time)}
+}
+
+type rateLimit struct {
+	n   int
+	per time.Duration
+
+	mu   sync.Mutex
+	seen map[string][]time.Time
+}
+
+func (l *rateLimit) Check(r *Request) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	k := string(r.Key.ToBytes())
+	times := l.seen[k]
+
+	for len(times) > 0 && r.Time.Sub(times[0]) >= l.per {
+		times = times[1:]
+	}
+
+	if len(times) >= l.n {
+		l.seen[k] = times
+		return ErrRateLimited
+	}
+
+	l.seen[k] = append(times, r.Time)
+
+	return nil
+}
+
+func (l *rateLimit) Release(r *Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	k := string(r.Key.ToBytes())
+	times := l.seen[k]
+
+	for i := len(times) - 1; i >= 0; i-- {
+		if times[i].Equal(r.Time) {
+			l.seen[k] = append(times[:i:i], times[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package signer isolates keys in a signing service.
+//
+// The server holds key.Signers, for example the keys of a keystore
+// directory read with LoadKeystore, and signs the unsigned part of
+// transactions (85 bytes) and block headers (103 bytes) over HTTP:
+//
+//	GET  /v1/keys   {"keys": [{"address": "umi1...", "publicKey": "<hex>"}]}
+//	POST /v1/sign   {"publicKey": "<hex>", "data": "<base64>"}
+//	                -> {"signature": "<hex>"}
+//
+// Every request is decoded and checked against a Policy before signing,
+// and every decision is written to the audit log as a JSON line. The
+// Client implements key.Signer on top of this protocol.
+package signer
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+const (
+	txLength     = 85
+	headerLength = 103
+)
+
+var (
+	ErrUnauthorized   = errors.New("signer: unauthorized")
+	ErrUnknownKey     = errors.New("signer: unknown key")
+	ErrInvalidRequest = errors.New("signer: invalid request")
+	ErrKeyMismatch    = errors.New("signer: signing key does not match sender or block key")
+)
+
+type Config struct {
+	// Signers hold the keys, for example loaded with LoadKeystore.
+	Signers []key.Signer
+	// Policy, if set, must allow every request.
+	Policy Policy
+	// Audit receives one JSON line per signing request.
+	Audit io.Writer
+	// Token, if set, is required as "Authorization: Bearer <token>".
+	Token string
+	Now   func() time.Time
+}
+
+// AuditEntry is a line of the audit log.
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Remote   string    `json:"remote"`
+	Address  string    `json:"address,omitempty"`
+	Kind     string    `json:"kind,omitempty"`
+	Hash     string    `json:"hash,omitempty"`
+	Decision string    `json:"decision"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+type Server struct {
+	signers map[string]key.Signer
+	order   []key.Signer
+	policy  Policy
+	token   string
+	now     func() time.Time
+
+	mu    sync.Mutex
+	audit io.Writer
+}
+
+func NewServer(c Config) *Server {
+	s := &Server{
+		signers: make(map[string]key.Signer, len(c.Signers)),
+		policy:  c.Policy,
+		token:   c.Token,
+		now:     c.Now,
+		audit:   c.Audit,
+	}
+
+	for _, k := range c.Signers {
+		if _, ok := s.signers[string(k.PublicKey().ToBytes())]; !ok {
+			s.order = append(s.order, k)
+		}
+
+		s.signers[string(k.PublicKey().ToBytes())] = k
+	}
+
+	if s.now == nil {
+		s.now = time.Now
+	}
+
+	return s
+}
+
+type jsonKey struct {
+	Address   string `json:"address"`
+	PublicKey string `json:"publicKey"`
+}
+
+type signRequest struct {
+	PublicKey string `json:"publicKey"`
+	Data      string `json:"data"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.token)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, signResponse{Error: ErrUnauthorized.Error()})
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/v1/keys" && r.Method == http.MethodGet:
+		keys := make([]jsonKey, len(s.order))
+		for i, k := range s.order {
+			keys[i] = jsonKey{
+				Address:   address.FromKey(k).ToBech32(),
+				PublicKey: hex.EncodeToString(k.PublicKey().ToBytes()),
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string][]jsonKey{"keys": keys})
+	case r.URL.Path == "/v1/sign" && r.Method == http.MethodPost:
+		s.handleSign(w, r)
+	default:
+		writeJSON(w, http.StatusNotFound, signResponse{Error: "not found"})
+	}
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	entry := AuditEntry{Time: s.now().UTC(), Remote: r.RemoteAddr}
+	r.Body = http.MaxBytesReader(w, r.Body, 4096)
+
+	sig, err := s.sign(r, &entry)
+	if err != nil {
+		entry.Decision, entry.Reason = "denied", err.Error()
+		s.log(entry)
+
+		writeJSON(w, status(err), signResponse{Error: err.Error()})
+
+		return
+	}
+
+	entry.Decision = "signed"
+	s.log(entry)
+
+	writeJSON(w, http.StatusOK, signResponse{Signature: hex.EncodeToString(sig)})
+}
+
+func (s *Server) sign(r *http.Request, entry *AuditEntry) ([]byte, error) {
+	var req signRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	pub, err := hex.DecodeString(req.PublicKey)
+	if err != nil {
+		return nil, ErrInvalidRequest
+	}
+
+	k, ok := s.signers[string(pub)]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	entry.Address = address.FromKey(k).ToBech32()
+
+	data, err := base64.StdEncoding.DecodeString(req.Data)
+	if err != nil {
+		return nil, ErrInvalidRequest
+	}
+
+	pr := &Request{Key: k.PublicKey(), Remote: r.RemoteAddr, Time: entry.Time}
+
+	switch len(data) {
+	case txLength:
+		t := transaction.NewTransaction()
+		copy(t.Bytes, data)
+
+		entry.Kind, entry.Hash = "transaction", hex.EncodeToString(t.Hash())
+
+		if t.Version() > transaction.DeleteTransitAddress {
+			return nil, fmt.Errorf("%w: unknown transaction version %d", ErrInvalidRequest, t.Version())
+		}
+
+		// Genesis transactions only appear in the genesis block.
+		if t.Version() == transaction.Genesis {
+			return nil, fmt.Errorf("%w: genesis transactions are not signed", ErrInvalidRequest)
+		}
+
+		if string(t.Sender().PublicKey().ToBytes()) != string(pub) {
+			return nil, ErrKeyMismatch
+		}
+
+		pr.Transaction = t
+	case headerLength:
+		b := block.NewBlock()
+		copy(b.Bytes, data)
+
+		entry.Kind, entry.Hash = "block", hex.EncodeToString(b.Hash())
+
+		if string(b.PublicKey().ToBytes()) != string(pub) {
+			return nil, ErrKeyMismatch
+		}
+
+		pr.Block = b
+	default:
+		return nil, fmt.Errorf("%w: expected %d or %d bytes, got %d", ErrInvalidRequest, txLength, headerLength, len(data))
+	}
+
+	if s.policy != nil {
+		if err := s.policy.Check(pr); err != nil {
+			return nil, err
+		}
+	}
+
+	sig, err := k.Sign(data)
+	if err != nil {
+		if rl, ok := s.policy.(Releaser); ok {
+			rl.Release(pr)
+		}
+
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+func (s *Server) log(e AuditEntry) {
+	if s.audit == nil {
+		return
+	}
+
+	b, _ := json.Marshal(e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, _ = s.audit.Write(append(b, '\n'))
+}
+
+func status(err error) int {
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrDenied), errors.Is(err, ErrKeyMismatch):
+		return http.StatusForbidden
+	case errors.Is(err, ErrUnknownKey):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidRequest):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package signer_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/signer"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func newTx(k key.Key, to *address.Address, v amount.Amount) *transaction.Transaction {
+	return transaction.NewTransaction().
+		SetVersion(transaction.Basic).
+		SetSender(address.FromKey(k)).
+		SetRecipient(to).
+		SetAmount(v).
+		SetNonce(1)
+}
+
+func startServer(t *testing.T, c signer.Config) *signer.Client {
+	srv := httptest.NewServer(signer.NewServer(c))
+	t.Cleanup(srv.Close)
+
+	return signer.NewClient(srv.URL, srv.Client()).SetToken(c.Token)
+}
+
+func TestSign(t *testing.T) {
+	sec := newKey()
+	c := startServer(t, signer.Config{Signers: []key.Signer{key.NewSigner(sec)}})
+
+	keys, err := c.Keys()
+	if err != nil || len(keys) != 1 || !bytes.Equal(keys[0].ToBytes(), sec.PublicKey().ToBytes()) {
+		t.Fatalf("Expected: %x, got: %v %v", sec.PublicKey().ToBytes(), keys, err)
+	}
+
+	s := c.Signer(keys[0])
+
+	tx := newTx(sec, address.FromKey(newKey()), 100)
+	if err := tx.SignWith(s); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := tx.Verify(); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	b := block.NewBlock().AppendTransaction(tx)
+	b.SetMerkleRootHash(b.CalculateMerkleRoot())
+
+	if err := b.SignWith(s); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if !b.Verify() {
+		t.Fatalf("Expected: %v, got: %v", true, false)
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	sec, other := newKey(), newKey()
+	c := startServer(t, signer.Config{Signers: []key.Signer{key.NewSigner(sec)}})
+
+	tests := []struct {
+		name string
+		key  *key.SecretKey
+		msg  []byte
+		err  error
+	}{
+		{"unknown key", other, newTx(other, address.FromKey(sec), 1).Bytes[:85], signer.ErrUnknownKey},
+		{"other sender", sec, newTx(other, address.FromKey(sec), 1).Bytes[:85], signer.ErrDenied},
+		{"invalid length", sec, []byte("hello"), signer.ErrInvalidRequest},
+		{"invalid version", sec, newTx(sec, address.FromKey(sec), 1).SetVersion(255).Bytes[:85], signer.ErrInvalidRequest},
+		{"genesis", sec, newTx(sec, address.FromKey(sec), 1).SetVersion(transaction.Genesis).Bytes[:85], signer.ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		if _, err := c.Signer(tt.key.PublicKey()).Sign(tt.msg); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: Expected: %v, got: %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestToken(t *testing.T) {
+	sec := newKey()
+	srv := httptest.NewServer(signer.NewServer(signer.Config{Signers: []key.Signer{key.NewSigner(sec)}, Token: "secret"}))
+	defer srv.Close()
+
+	if _, err := signer.NewClient(srv.URL, nil).Keys(); !errors.Is(err, signer.ErrUnauthorized) {
+		t.Fatalf("Expected: %v, got: %v", signer.ErrUnauthorized, err)
+	}
+
+	if _, err := signer.NewClient(srv.URL, nil).SetToken("wrong").Keys(); !errors.Is(err, signer.ErrUnauthorized) {
+		t.Fatalf("Expected: %v, got: %v", signer.ErrUnauthorized, err)
+	}
+
+	if _, err := signer.NewClient(srv.URL, nil).SetToken("secret").Keys(); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	sec := newKey()
+	allowed := address.FromKey(newKey())
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var audit bytes.Buffer
+
+	c := startServer(t, signer.Config{
+		Signers: []key.Signer{key.NewSigner(sec)},
+		Policy: signer.All(
+			signer.AllowRecipients(allowed),
+			signer.MaxValue(1000),
+			signer.RateLimit(2, time.Minute),
+		),
+		Audit: &audit,
+		Now:   func() time.Time { return now },
+	})
+	s := c.Signer(sec.PublicKey())
+
+	tests := []struct {
+		name string
+		tx   *transaction.Transaction
+		err  error
+	}{
+		{"allowed", newTx(sec, allowed, 1000), nil},
+		{"recipient", newTx(sec, address.FromKey(newKey()), 1), signer.ErrDenied},
+		{"value", newTx(sec, allowed, 1001), signer.ErrDenied},
+		{"allowed", newTx(sec, allowed, 1), nil},
+		{"rate", newTx(sec, allowed, 1), signer.ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		if err := tt.tx.SignWith(s); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: Expected: %v, got: %v", tt.name, tt.err, err)
+		}
+	}
+
+	now = now.Add(time.Minute)
+
+	if err := newTx(sec, allowed, 1).SignWith(s); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	var decisions []string
+
+	dec := json.NewDecoder(&audit)
+
+	for dec.More() {
+		var e signer.AuditEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+
+		if e.Address != address.FromKey(sec).ToBech32() || e.Kind != "transaction" || e.Hash == "" {
+			t.Fatalf("Expected: audit entry for %s, got: %+v", address.FromKey(sec).ToBech32(), e)
+		}
+
+		decisions = append(decisions, e.Decision)
+	}
+
+	expected := []string{"signed", "denied", "denied", "signed", "denied", "signed"}
+	if len(decisions) != len(expected) {
+		t.Fatalf("Expected: %v, got: %v", expected, decisions)
+	}
+
+	for i := range expected {
+		if decisions[i] != expected[i] {
+			t.Fatalf("Expected: %v, got: %v", expected, decisions)
+		}
+	}
+}
+
+func TestTransactionPolicy(t *testing.T) {
+	sec := newKey()
+	errLimit := errors.New("over limit")
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p := signer.TransactionPolicy(func(tx *transaction.Transaction, at time.Time) error {
+		if !at.Equal(now) {
+			t.Fatalf("Expected: %v, got: %v", now, at)
+		}
+
+		if tx.Value() > 10 {
+			return errLimit
+		}
+
+		return nil
+	})
+
+	if err := p.Check(&signer.Request{Transaction: newTx(sec, address.FromKey(newKey()), 10), Time: now}); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := p.Check(&signer.Request{Transaction: newTx(sec, address.FromKey(newKey()), 11), Time: now}); !errors.Is(err, signer.ErrDenied) {
+		t.Fatalf("Expected: %v, got: %v", signer.ErrDenied, err)
+	}
+
+	if err := p.Check(&signer.Request{Block: block.NewBlock(), Time: now}); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+}
+
+type flakySigner struct {
+	key.Signer
+	fail int
+}
+
+func (s *flakySigner) Sign(msg []byte) ([]byte, error) {
+	if s.fail > 0 {
+		s.fail--
+		return nil, errors.New("agent unavailable")
+	}
+
+	return s.Signer.Sign(msg)
+}
+
+func TestRateLimitRelease(t *testing.T) {
+	sec := newKey()
+	allowed := address.FromKey(newKey())
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := startServer(t, signer.Config{
+		Signers: []key.Signer{&flakySigner{Signer: key.NewSigner(sec), fail: 1}},
+		Policy:  signer.All(signer.RateLimit(1, time.Minute), signer.AllowRecipients(allowed)),
+		Now:     func() time.Time { return now },
+	})
+	s := c.Signer(sec.PublicKey())
+
+	if err := newTx(sec, allowed, 1).SignWith(s); err == nil {
+		t.Fatalf("Expected: signing error, got: nil")
+	}
+
+	tests := []struct {
+		name string
+		tx   *transaction.Transaction
+		err  error
+	}{
+		{"denied", newTx(sec, address.FromKey(newKey()), 1), signer.ErrDenied},
+		{"allowed", newTx(sec, allowed, 1), nil},
+		{"rate", newTx(sec, allowed, 1), signer.ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		if err := tt.tx.SignWith(s); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: Expected: %v, got: %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestLoadKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	k0, k1 := newKey(), newKey()
+
+	for name, k := range map[string]*key.SecretKey{"b.pem": k1, "a.pem": k0} {
+		b, _ := k.MarshalPEM()
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b, 0o600); err != nil {
+			t.Fatalf("Expected: nil, got: %v", err)
+		}
+	}
+
+	_ = ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0o600)
+
+	signers, err := signer.LoadKeystore(dir)
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	keys, err := startServer(t, signer.Config{Signers: signers}).Keys()
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if len(keys) != 2 || !bytes.Equal(keys[0].ToBytes(), k0.PublicKey().ToBytes()) || !bytes.Equal(keys[1].ToBytes(), k1.PublicKey().ToBytes()) {
+		t.Fatalf("Expected: keys of a.pem and b.pem, got: %v", keys)
+	}
+
+	_ = ioutil.WriteFile(filepath.Join(dir, "c.pem"), []byte("garbage"), 0o600)
+
+	if _, err := signer.LoadKeystore(dir); err == nil {
+		t.Fatalf("Expected: error for c.pem, got: nil")
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package sshagent signs with ed25519 keys held by an ssh-agent, using the
+// agent protocol (draft-miller-ssh-agent) over its Unix socket.
+//
+// An ssh-ed25519 signature is a plain ed25519 signature, so the keys can
+// sign transactions and blocks without ever leaving the agent.
+package sshagent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/key"
+)
+
+const (
+	agentFailure           = 5
+	agentRequestIdentities = 11
+	agentIdentitiesAnswer  = 12
+	agentSignRequest       = 13
+	agentSignResponse      = 14
+
+	keyType = "ssh-ed25519"
+
+	// maxMessage bounds the size of agent replies.
+	maxMessage = 256 * 1024
+)
+
+var (
+	ErrNoSocket        = errors.New("sshagent: SSH_AUTH_SOCK is not set")
+	ErrAgentFailure    = errors.New("sshagent: agent refused the request")
+	ErrInvalidResponse = errors.New("sshagent: invalid agent response")
+	ErrKeyNotFound     = errors.New("sshagent: key not found in agent")
+)
+
+// Identity is an ed25519 key held by the agent.
+type Identity struct {
+	PublicKey *key.PublicKey
+	Comment   string
+}
+
+// Address returns the umi address of the identity.
+func (i Identity) Address() *address.Address {
+	return address.FromKey(i.PublicKey)
+}
+
+type Client struct {
+	mu   sync.Mutex
+	conn io.ReadWriteCloser
+}
+
+// Dial connects to the agent listening on the Unix socket path, an empty
+// path means $SSH_AUTH_SOCK.
+func Dial(path string) (*Client, error) {
+	if path == "" {
+		path = os.Getenv("SSH_AUTH_SOCK")
+	}
+
+	if path == "" {
+		return nil, ErrNoSocket
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(conn), nil
+}
+
+// NewClient speaks the agent protocol over conn.
+func NewClient(conn io.ReadWriteCloser) *Client {
+	return &Client{conn: conn}
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Identities lists the ed25519 keys of the agent, other key types are
+// skipped.
+func (c *Client) Identities() ([]Identity, error) {
+	res, err := c.call([]byte{agentRequestIdentities})
+	if err != nil {
+		return nil, err
+	}
+
+	if res[0] != agentIdentitiesAnswer {
+		return nil, ErrInvalidResponse
+	}
+
+	r := reader(res[1:])
+	n := r.uint32()
+
+	var ids []Identity
+
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		blob, comment := r.string(), r.string()
+
+		if pub, ok := parseKey(blob); ok {
+			ids = append(ids, Identity{PublicKey: key.NewPublicKey(pub), Comment: string(comment)})
+		}
+	}
+
+	if r.err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	return ids, nil
+}
+
+// Addresses lists the ed25519 keys of the agent as umi addresses.
+func (c *Client) Addresses() ([]*address.Address, error) {
+	ids, err := c.Identities()
+	if err != nil {
+		return nil, err
+	}
+
+	adr := make([]*address.Address, len(ids))
+	for i, id := range ids {
+		adr[i] = id.Address()
+	}
+
+	return adr, nil
+}
+
+// Signer returns a key.Signer for the agent key pub.
+func (c *Client) Signer(pub *key.PublicKey) (key.Signer, error) {
+	ids, err := c.Identities()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if bytes.Equal(id.PublicKey.ToBytes(), pub.ToBytes()) {
+			return &signer{client: c, pub: id.PublicKey}, nil
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+func (c *Client) sign(pub *key.PublicKey, msg []byte) ([]byte, error) {
+	req := []byte{agentSignRequest}
+	req = appendString(req, marshalKey(pub.ToBytes()))
+	req = appendString(req, msg)
+	req = append(req, 0, 0, 0, 0) // flags
+
+	res, err := c.call(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res[0] != agentSignResponse {
+		return nil, ErrInvalidResponse
+	}
+
+	r := reader(res[1:])
+	blob := reader(r.string())
+	typ, sig := blob.string(), blob.string()
+
+	if r.err != nil || blob.err != nil || string(typ) != keyType || len(sig) != 64 {
+		return nil, ErrInvalidResponse
+	}
+
+	if !pub.VerifySignature(sig, msg) {
+		return nil, fmt.Errorf("%w: signature does not verify", ErrInvalidResponse)
+	}
+
+	return sig, nil
+}
+
+// call sends one request and reads one reply, a failure reply becomes
+// ErrAgentFailure.
+func (c *Client) call(req []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msg := make([]byte, 4, 4+len(req))
+	binary.BigEndian.PutUint32(msg, uint32(len(req)))
+	msg = append(msg, req...)
+
+	if _, err := c.conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	var hdr [4]byte
+	if _, err := io.ReadFull(c.conn, hdr[:]); err != nil {
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n == 0 || n > maxMessage {
+		return nil, ErrInvalidResponse
+	}
+
+	res := make([]byte, n)
+	if _, err := io.ReadFull(c.conn, res); err != nil {
+		return nil, err
+	}
+
+	if res[0] == agentFailure {
+		return nil, ErrAgentFailure
+	}
+
+	return res, nil
+}
+
+type signer struct {
+	client *Client
+	pub    *key.PublicKey
+}
+
+func (s *signer) PublicKey() *key.PublicKey {
+	return s.pub
+}
+
+func (s *signer) Sign(msg []byte) ([]byte, error) {
+	return s.client.sign(s.pub, msg)
+}
+
+func marshalKey(pub []byte) []byte {
+	return appendString(appendString(nil, []byte(keyType)), pub)
+}
+
+func parseKey(blob []byte) ([]byte, bool) {
+	r := reader(blob)
+	typ, pub := r.string(), r.string()
+
+	return pub, r.err == nil && string(typ) == keyType && len(pub) == 32 && len(r.b) == 0
+}
+
+func appendString(b, s []byte) []byte {
+	var n [4]byte
+
+	binary.BigEndian.PutUint32(n[:], uint32(len(s)))
+
+	return append(append(b, n[:]...), s...)
+}
+
+// wireReader decodes SSH wire types, the first error sticks.
+type wireReader struct {
+	b   []byte
+	err error
+}
+
+func reader(b []byte) *wireReader {
+	return &wireReader{b: b}
+}
+
+func (r *wireReader) uint32() uint32 {
+	if r.err != nil || len(r.b) < 4 {
+		r.err = ErrInvalidResponse
+		return 0
+	}
+
+	v := binary.BigEndian.Uint32(r.b)
+	r.b = r.b[4:]
+
+	return v
+}
+
+func (r *wireReader) string() []byte {
+	n := r.uint32()
+	if r.err != nil || uint32(len(r.b)) < n {
+		r.err = ErrInvalidResponse
+		return nil
+	}
+
+	s := r.b[:n]
+	r.b = r.b[n:]
+
+	return s
+}
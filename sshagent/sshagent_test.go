@@ -0,0 +1,257 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sshagent_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/sshagent"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+// agent is a minimal in-process ssh-agent holding ed25519 keys and one
+// RSA identity it cannot sign with. A locked agent refuses to sign.
+type agent struct {
+	keys   []*key.SecretKey
+	locked bool
+}
+
+func sshString(b []byte) []byte {
+	var n [4]byte
+
+	binary.BigEndian.PutUint32(n[:], uint32(len(b)))
+
+	return append(n[:], b...)
+}
+
+func keyBlob(typ string, pub []byte) []byte {
+	return append(sshString([]byte(typ)), sshString(pub)...)
+}
+
+func (a *agent) serve(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			for {
+				var hdr [4]byte
+				if _, err := io.ReadFull(conn, hdr[:]); err != nil {
+					return
+				}
+
+				req := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+				if _, err := io.ReadFull(conn, req); err != nil {
+					return
+				}
+
+				res := a.handle(req)
+				_, _ = conn.Write(sshString(res))
+			}
+		}()
+	}
+}
+
+func (a *agent) handle(req []byte) []byte {
+	switch req[0] {
+	case 11:
+		var n [4]byte
+
+		binary.BigEndian.PutUint32(n[:], uint32(len(a.keys)+1))
+		res := append([]byte{12}, n[:]...)
+		res = append(res, sshString(keyBlob("ssh-rsa", []byte{1, 0, 1}))...)
+		res = append(res, sshString([]byte("rsa"))...)
+
+		for i, k := range a.keys {
+			res = append(res, sshString(keyBlob("ssh-ed25519", k.PublicKey().ToBytes()))...)
+			res = append(res, sshString([]byte{'k', byte('0' + i)})...)
+		}
+
+		return res
+	case 13:
+		if a.locked {
+			break
+		}
+
+		blobLen := binary.BigEndian.Uint32(req[1:5])
+		blob := req[5 : 5+blobLen]
+		data := req[9+blobLen : 9+blobLen+binary.BigEndian.Uint32(req[5+blobLen:9+blobLen])]
+
+		for _, k := range a.keys {
+			if bytes.Equal(blob, keyBlob("ssh-ed25519", k.PublicKey().ToBytes())) {
+				sig := keyBlob("ssh-ed25519", k.Sign(data))
+				return append([]byte{14}, sshString(sig)...)
+			}
+		}
+	}
+
+	return []byte{5}
+}
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func startAgent(t *testing.T, a *agent) string {
+	dir, err := ioutil.TempDir("", "sshagent")
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	path := filepath.Join(dir, "agent.sock")
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = l.Close()
+		_ = os.RemoveAll(dir)
+	})
+
+	go a.serve(l)
+
+	return path
+}
+
+func TestIdentities(t *testing.T) {
+	k0, k1 := newKey(), newKey()
+
+	c, err := sshagent.Dial(startAgent(t, &agent{keys: []*key.SecretKey{k0, k1}}))
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+	defer c.Close()
+
+	ids, err := c.Identities()
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if len(ids) != 2 || ids[0].Comment != "k0" || !bytes.Equal(ids[1].PublicKey.ToBytes(), k1.PublicKey().ToBytes()) {
+		t.Fatalf("unexpected identities: %+v", ids)
+	}
+
+	adr, _ := c.Addresses()
+	if exp := address.FromKey(k0).ToBech32(); adr[0].ToBech32() != exp {
+		t.Fatalf("Expected: %s, got: %s", exp, adr[0].ToBech32())
+	}
+}
+
+func TestSigner(t *testing.T) {
+	k := newKey()
+
+	c, _ := sshagent.Dial(startAgent(t, &agent{keys: []*key.SecretKey{newKey(), k}}))
+	defer c.Close()
+
+	s, err := c.Signer(k.PublicKey())
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	tx := transaction.NewTransaction().SetSender(address.FromKey(k)).
+		SetRecipient(address.FromKey(newKey())).SetValue(100)
+
+	if err := tx.SignWith(s); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := tx.Verify(); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	b := block.NewBlock().AppendTransaction(tx)
+	b.SetMerkleRootHash(b.CalculateMerkleRoot())
+
+	if err := b.SignWith(s); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if !b.Verify() {
+		t.Fatalf("Expected: block signature to verify")
+	}
+
+	if _, err := c.Signer(newKey().PublicKey()); !errors.Is(err, sshagent.ErrKeyNotFound) {
+		t.Fatalf("Expected: %v, got: %v", sshagent.ErrKeyNotFound, err)
+	}
+}
+
+func TestAgentFailure(t *testing.T) {
+	k := newKey()
+
+	c, _ := sshagent.Dial(startAgent(t, &agent{keys: []*key.SecretKey{k}, locked: true}))
+	defer c.Close()
+
+	s, err := c.Signer(k.PublicKey())
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if _, err := s.Sign([]byte("msg")); !errors.Is(err, sshagent.ErrAgentFailure) {
+		t.Fatalf("Expected: %v, got: %v", sshagent.ErrAgentFailure, err)
+	}
+
+	tx := transaction.NewTransaction().SetSender(address.FromKey(k))
+	if err := tx.SignWith(s); !errors.Is(err, sshagent.ErrAgentFailure) {
+		t.Fatalf("Expected: %v, got: %v", sshagent.ErrAgentFailure, err)
+	}
+}
+
+func TestDial(t *testing.T) {
+	path := startAgent(t, &agent{})
+
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	if _, err := sshagent.Dial(""); !errors.Is(err, sshagent.ErrNoSocket) {
+		t.Fatalf("Expected: %v, got: %v", sshagent.ErrNoSocket, err)
+	}
+
+	t.Setenv("SSH_AUTH_SOCK", path)
+
+	c, err := sshagent.Dial("")
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+	defer c.Close()
+
+	if ids, err := c.Identities(); err != nil || len(ids) != 0 {
+		t.Fatalf("Expected: no identities, got: %v, %v", ids, err)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package structure
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+var (
+	ErrExists         = errors.New("structure: already exists")
+	ErrNotFound       = errors.New("structure: not found")
+	ErrNotOwner       = errors.New("structure: sender is not the owner")
+	ErrInvalidAddress = errors.New("structure: invalid address")
+	ErrTransitExists  = errors.New("structure: transit address already exists")
+	ErrNotTransit     = errors.New("structure: not a transit address")
+)
+
+// Registry keeps the current state of every structure by prefix. It does
+// not check signatures, transactions are expected to pass
+// transaction.Verify before they are applied.
+type Registry struct {
+	mu         sync.RWMutex
+	structures map[string]*Structure
+}
+
+func NewRegistry() *Registry {
+	return &Registry{structures: make(map[string]*Structure)}
+}
+
+// Get returns a copy of the structure registered under prefix.
+func (r *Registry) Get(prefix string) (*Structure, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.structures[prefix]
+	if !ok {
+		return nil, false
+	}
+
+	return s.clone(), true
+}
+
+func (r *Registry) Prefixes() []string {
+	r.mu.RLock()
+	p := make([]string, 0, len(r.structures))
+
+	for k := range r.structures {
+		p = append(p, k)
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(p)
+
+	return p
+}
+
+// ApplyBlock applies every transaction of b. The transactions are applied
+// to a copy first, so a block with a rejected transaction leaves the
+// registry unchanged.
+func (r *Registry) ApplyBlock(b *block.Block) error {
+	return r.Update(func(tmp *Registry) error {
+		for i := uint16(0); i < b.TxCount(); i++ {
+			if err := tmp.apply(b.Transaction(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Update calls fn with a copy of the registry and keeps the changes fn made
+// to it only if fn succeeds. The registry is locked until fn returns, so fn
+// must use the copy and not r.
+func (r *Registry) Update(fn func(tmp *Registry) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tmp := &Registry{structures: make(map[string]*Structure, len(r.structures))}
+	for k, s := range r.structures {
+		tmp.structures[k] = s.clone()
+	}
+
+	if err := fn(tmp); err != nil {
+		return err
+	}
+
+	r.structures = tmp.structures
+
+	return nil
+}
+
+// Apply changes the registry according to t. Transactions that do not
+// describe structures are ignored. A rejected transaction leaves the
+// registry unchanged.
+func (r *Registry) Apply(t *transaction.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.apply(t)
+}
+
+func (r *Registry) apply(t *transaction.Transaction) error {
+	switch t.Version() {
+	case transaction.Genesis, transaction.Basic:
+		return nil
+	case transaction.CreateSmartContract:
+		return r.create(t)
+	case transaction.UpdateSmartContract:
+		return r.update(t)
+	case transaction.UpdateProfitAddress, transaction.UpdateFeeAddress,
+		transaction.CreateTransitAddress, transaction.DeleteTransitAddress:
+		return r.updateAddress(t)
+	default:
+		return transaction.ErrInvalidVersion
+	}
+}
+
+func (r *Registry) create(t *transaction.Transaction) error {
+	pfx := t.Prefix()
+	if _, ok := r.structures[pfx]; ok {
+		return ErrExists
+	}
+
+	snd := t.Sender()
+	dflt := address.FromBytes(snd.Bytes).SetPrefix(pfx)
+
+	r.structures[pfx] = &Structure{
+		Prefix:        pfx,
+		Name:          t.Name(),
+		Owner:         snd,
+		ProfitPercent: t.ProfitPercent(),
+		FeePercent:    t.FeePercent(),
+		ProfitAddress: dflt,
+		FeeAddress:    address.FromBytes(dflt.Bytes),
+		transit:       make(map[string]struct{}),
+	}
+
+	return nil
+}
+
+func (r *Registry) update(t *transaction.Transaction) error {
+	s, ok := r.structures[t.Prefix()]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if !s.IsOwner(t.Sender()) {
+		return ErrNotOwner
+	}
+
+	s.Name = t.Name()
+	s.ProfitPercent = t.ProfitPercent()
+	s.FeePercent = t.FeePercent()
+
+	return nil
+}
+
+func (r *Registry) updateAddress(t *transaction.Transaction) error {
+	rcp := t.Recipient()
+
+	s, ok := r.structures[rcp.Prefix()]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if !s.IsOwner(t.Sender()) {
+		return ErrNotOwner
+	}
+
+	service := bytes.Equal(rcp.Bytes, s.ProfitAddress.Bytes) || bytes.Equal(rcp.Bytes, s.FeeAddress.Bytes)
+
+	switch t.Version() {
+	case transaction.UpdateProfitAddress:
+		if s.IsTransit(rcp) {
+			return ErrInvalidAddress
+		}
+
+		s.ProfitAddress = rcp
+	case transaction.UpdateFeeAddress:
+		if s.IsTransit(rcp) {
+			return ErrInvalidAddress
+		}
+
+		s.FeeAddress = rcp
+	case transaction.CreateTransitAddress:
+		if service {
+			return ErrInvalidAddress
+		}
+
+		if s.IsTransit(rcp) {
+			return ErrTransitExists
+		}
+
+		s.transit[string(rcp.Bytes)] = struct{}{}
+	case transaction.DeleteTransitAddress:
+		if !s.IsTransit(rcp) {
+			return ErrNotTransit
+		}
+
+		delete(s.transit, string(rcp.Bytes))
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package structure_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/block"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/structure"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func create(sec *key.SecretKey, pfx string) *transaction.Transaction {
+	return transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).
+		SetSender(address.FromKey(sec)).SetPrefix(pfx).
+		SetProfitPercent(200).SetFeePercent(1000).Sign(*sec)
+}
+
+func change(sec *key.SecretKey, ver uint8, rcp *address.Address) *transaction.Transaction {
+	return transaction.NewTransaction().SetVersion(ver).
+		SetSender(address.FromKey(sec)).SetRecipient(rcp).Sign(*sec)
+}
+
+func TestCreate(t *testing.T) {
+	own := newKey()
+	reg := structure.NewRegistry()
+
+	if err := reg.Apply(create(own, "aaa")); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	s, ok := reg.Get("aaa")
+	if !ok {
+		t.Fatal("Expected structure aaa")
+	}
+
+	exp := address.FromKey(own).SetPrefix("aaa").ToBech32()
+
+	if s.ProfitPercent != 200 || s.FeePercent != 1000 ||
+		s.ProfitAddress.ToBech32() != exp || s.FeeAddress.ToBech32() != exp ||
+		s.Owner.ToBech32() != address.FromKey(own).ToBech32() {
+		t.Fatalf("Unexpected structure: %+v", s)
+	}
+
+	if err := reg.Apply(create(newKey(), "aaa")); !errors.Is(err, structure.ErrExists) {
+		t.Fatalf("Expected: %v, got: %v", structure.ErrExists, err)
+	}
+
+	if !reflect.DeepEqual(reg.Prefixes(), []string{"aaa"}) {
+		t.Fatalf("Expected: [aaa], got: %v", reg.Prefixes())
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	own, other := newKey(), newKey()
+	reg := structure.NewRegistry()
+	_ = reg.Apply(create(own, "bbb"))
+
+	upd := func(sec *key.SecretKey) *transaction.Transaction {
+		return transaction.NewTransaction().SetVersion(transaction.UpdateSmartContract).
+			SetSender(address.FromKey(sec)).SetPrefix("bbb").
+			SetProfitPercent(500).SetFeePercent(0).Sign(*sec)
+	}
+
+	if err := reg.Apply(upd(other)); !errors.Is(err, structure.ErrNotOwner) {
+		t.Fatalf("Expected: %v, got: %v", structure.ErrNotOwner, err)
+	}
+
+	if err := reg.Apply(upd(own)); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	s, _ := reg.Get("bbb")
+	if s.ProfitPercent != 500 || s.FeePercent != 0 {
+		t.Fatalf("Unexpected structure: %+v", s)
+	}
+
+	// The owner is identified by its key whatever the sender prefix is.
+	tx := upd(own).SetSender(address.FromKey(own).SetPrefix("bbb")).Sign(*own)
+	if err := reg.Apply(tx); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+}
+
+func TestAddresses(t *testing.T) {
+	own := newKey()
+	reg := structure.NewRegistry()
+	_ = reg.Apply(create(own, "ccc"))
+
+	prf := address.FromKey(newKey()).SetPrefix("ccc")
+	fee := address.FromKey(newKey()).SetPrefix("ccc")
+	trn := address.FromKey(newKey()).SetPrefix("ccc")
+
+	steps := []struct {
+		desc string
+		tx   *transaction.Transaction
+		err  error
+	}{
+		{"profit", change(own, transaction.UpdateProfitAddress, prf), nil},
+		{"fee", change(own, transaction.UpdateFeeAddress, fee), nil},
+		{"not owner", change(newKey(), transaction.UpdateFeeAddress, prf), structure.ErrNotOwner},
+		{"unknown structure", change(own, transaction.UpdateFeeAddress, address.FromKey(own).SetPrefix("zzz")), structure.ErrNotFound},
+		{"transit", change(own, transaction.CreateTransitAddress, trn), nil},
+		{"transit twice", change(own, transaction.CreateTransitAddress, trn), structure.ErrTransitExists},
+		{"transit profit", change(own, transaction.CreateTransitAddress, prf), structure.ErrInvalidAddress},
+		{"profit transit", change(own, transaction.UpdateProfitAddress, trn), structure.ErrInvalidAddress},
+	}
+
+	for _, st := range steps {
+		if err := reg.Apply(st.tx); !errors.Is(err, st.err) {
+			t.Fatalf("%s: expected: %v, got: %v", st.desc, st.err, err)
+		}
+	}
+
+	s, _ := reg.Get("ccc")
+	if s.ProfitAddress.ToBech32() != prf.ToBech32() || s.FeeAddress.ToBech32() != fee.ToBech32() {
+		t.Fatalf("Unexpected structure: %+v", s)
+	}
+
+	if !s.IsTransit(trn) || len(s.TransitAddresses()) != 1 {
+		t.Fatalf("Expected transit address %s", trn.ToBech32())
+	}
+
+	if err := reg.Apply(change(own, transaction.DeleteTransitAddress, trn)); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if err := reg.Apply(change(own, transaction.DeleteTransitAddress, trn)); !errors.Is(err, structure.ErrNotTransit) {
+		t.Fatalf("Expected: %v, got: %v", structure.ErrNotTransit, err)
+	}
+
+	// Copies returned by Get are not affected by later changes.
+	if !s.IsTransit(trn) {
+		t.Fatal("Expected the copy to keep the transit address")
+	}
+}
+
+func TestApplyBlock(t *testing.T) {
+	own := newKey()
+
+	b := block.NewBlock()
+	b.AppendTransaction(create(own, "ddd"))
+	b.AppendTransaction(transaction.NewTransaction().SetSender(address.FromKey(own)).
+		SetRecipient(address.FromKey(newKey())).SetValue(1).Sign(*own))
+	b.AppendTransaction(create(newKey(), "eee"))
+
+	reg := structure.NewRegistry()
+	if err := reg.ApplyBlock(b); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(reg.Prefixes(), []string{"ddd", "eee"}) {
+		t.Fatalf("Expected: [ddd eee], got: %v", reg.Prefixes())
+	}
+}
+
+func TestApplyBlockRejected(t *testing.T) {
+	own := newKey()
+
+	reg := structure.NewRegistry()
+	if err := reg.Apply(create(own, "ddd")); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	trn := address.FromKey(newKey()).SetPrefix("ddd")
+
+	b := block.NewBlock()
+	b.AppendTransaction(create(newKey(), "eee"))
+	b.AppendTransaction(change(own, transaction.CreateTransitAddress, trn))
+	b.AppendTransaction(create(newKey(), "ddd"))
+
+	if err := reg.ApplyBlock(b); !errors.Is(err, structure.ErrExists) {
+		t.Fatalf("Expected: %v, got: %v", structure.ErrExists, err)
+	}
+
+	if !reflect.DeepEqual(reg.Prefixes(), []string{"ddd"}) {
+		t.Fatalf("Expected: [ddd], got: %v", reg.Prefixes())
+	}
+
+	if s, _ := reg.Get("ddd"); s.IsTransit(trn) {
+		t.Fatal("Expected the transit address of the rejected block to be dropped")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package structure models structures (smart contracts) as they are created
+// and changed by CreateSmartContract, UpdateSmartContract,
+// UpdateProfitAddress, UpdateFeeAddress, CreateTransitAddress and
+// DeleteTransitAddress transactions.
+package structure
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/umi-top/umi-core/address"
+)
+
+type Structure struct {
+	Prefix        string
+	Name          string
+	Owner         *address.Address
+	ProfitPercent uint16
+	FeePercent    uint16
+	ProfitAddress *address.Address
+	FeeAddress    *address.Address
+
+	transit map[string]struct{}
+}
+
+func (s *Structure) IsOwner(a *address.Address) bool {
+	return bytes.Equal(s.Owner.Bytes[2:], a.Bytes[2:])
+}
+
+func (s *Structure) IsTransit(a *address.Address) bool {
+	_, ok := s.transit[string(a.Bytes)]
+	return ok
+}
+
+// TransitAddresses returns the transit addresses in byte order.
+func (s *Structure) TransitAddresses() []*address.Address {
+	keys := make([]string, 0, len(s.transit))
+	for k := range s.transit {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	res := make([]*address.Address, len(keys))
+	for i, k := range keys {
+		res[i] = address.FromBytes([]byte(k))
+	}
+
+	return res
+}
+
+func (s *Structure) clone() *Structure {
+	c := *s
+	c.Owner = address.FromBytes(s.Owner.Bytes)
+	c.ProfitAddress = address.FromBytes(s.ProfitAddress.Bytes)
+	c.FeeAddress = address.FromBytes(s.FeeAddress.Bytes)
+	c.transit = make(map[string]struct{}, len(s.transit))
+
+	for k := range s.transit {
+		c.transit[k] = struct{}{}
+	}
+
+	return &c
+}
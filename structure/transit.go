@@ -0,0 +1,77 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package structure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/umi-top/umi-core/transaction"
+	"github.com/umi-top/umi-core/util"
+)
+
+var (
+	ErrGenesisTransfer = errors.New("structure: transfer from or to a genesis address")
+	ErrTransitRequired = errors.New("structure: funds can only leave a structure through a transit address")
+)
+
+// CheckTransfer applies the transit rules to a Basic transaction:
+//
+//   - genesis addresses can neither send nor receive;
+//   - both prefixes must be umi or belong to a registered structure;
+//   - transfers within a prefix and from umi addresses are allowed;
+//   - funds leave a structure, to umi or to another structure, only from
+//     one of its transit addresses or from its profit or fee address.
+//
+// Other transaction versions are not affected.
+func (r *Registry) CheckTransfer(t *transaction.Transaction) error {
+	if t.Version() != transaction.Basic {
+		return nil
+	}
+
+	snd, rcp := t.Sender(), t.Recipient()
+	ps, pr := snd.Prefix(), rcp.Prefix()
+
+	if ps == util.GenesisPrefix || pr == util.GenesisPrefix {
+		return ErrGenesisTransfer
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range []string{ps, pr} {
+		if _, ok := r.structures[p]; !ok && p != util.UmiPrefix {
+			return fmt.Errorf("%w: prefix %q", ErrNotFound, p)
+		}
+	}
+
+	if ps == pr || ps == util.UmiPrefix {
+		return nil
+	}
+
+	s := r.structures[ps]
+	if s.IsTransit(snd) || bytes.Equal(snd.Bytes, s.ProfitAddress.Bytes) || bytes.Equal(snd.Bytes, s.FeeAddress.Bytes) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s is not a transit address of %q", ErrTransitRequired, snd.ToBech32(), ps)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package structure_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/structure"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func TestCheckTransfer(t *testing.T) {
+	own := newKey()
+	reg := structure.NewRegistry()
+	_ = reg.Apply(create(own, "aaa"))
+	_ = reg.Apply(create(newKey(), "bbb"))
+
+	umi := address.FromKey(newKey())
+	usr := address.FromKey(newKey()).SetPrefix("aaa")
+	oth := address.FromKey(newKey()).SetPrefix("aaa")
+	trn := address.FromKey(newKey()).SetPrefix("aaa")
+	srv := address.FromKey(own).SetPrefix("aaa")
+	bbb := address.FromKey(newKey()).SetPrefix("bbb")
+	gen := address.FromKey(newKey()).SetPrefix("genesis")
+	unk := address.FromKey(newKey()).SetPrefix("zzz")
+
+	_ = reg.Apply(change(own, transaction.CreateTransitAddress, trn))
+
+	cases := []struct {
+		desc     string
+		snd, rcp *address.Address
+		err      error
+	}{
+		{"umi to umi", umi, address.FromKey(newKey()), nil},
+		{"umi to structure", umi, usr, nil},
+		{"within structure", usr, oth, nil},
+		{"structure to umi", usr, umi, structure.ErrTransitRequired},
+		{"structure to structure", usr, bbb, structure.ErrTransitRequired},
+		{"transit to umi", trn, umi, nil},
+		{"transit to structure", trn, bbb, nil},
+		{"service address to umi", srv, umi, nil},
+		{"from genesis", gen, umi, structure.ErrGenesisTransfer},
+		{"to genesis", umi, gen, structure.ErrGenesisTransfer},
+		{"unknown recipient prefix", umi, unk, structure.ErrNotFound},
+		{"unknown sender prefix", unk, umi, structure.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			tx := transaction.NewTransaction().SetSender(tc.snd).SetRecipient(tc.rcp).SetValue(1)
+			if err := reg.CheckTransfer(tx); !errors.Is(err, tc.err) {
+				t.Fatalf("Expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+
+	_ = reg.Apply(change(own, transaction.DeleteTransitAddress, trn))
+
+	tx := transaction.NewTransaction().SetSender(trn).SetRecipient(umi).SetValue(1)
+	if err := reg.CheckTransfer(tx); !errors.Is(err, structure.ErrTransitRequired) {
+		t.Fatalf("Expected: %v, got: %v", structure.ErrTransitRequired, err)
+	}
+
+	// Only Basic transactions are governed by the transit rules.
+	if err := reg.CheckTransfer(change(own, transaction.UpdateFeeAddress, usr)); err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+}
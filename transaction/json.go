@@ -0,0 +1,174 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package transaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/util"
+)
+
+var (
+	ErrInvalidJSON = errors.New("transaction: invalid json")
+	ErrInvalidHash = errors.New("transaction: invalid hash")
+)
+
+type jsonTransaction struct {
+	Hash          string           `json:"hash"`
+	Version       *uint8           `json:"version"`
+	Sender        *address.Address `json:"sender"`
+	Recipient     *address.Address `json:"recipient,omitempty"`
+	Value         *uint64          `json:"value,omitempty"`
+	Prefix        *string          `json:"prefix,omitempty"`
+	Name          *string          `json:"name,omitempty"`
+	ProfitPercent *uint16          `json:"profitPercent,omitempty"`
+	FeePercent    *uint16          `json:"feePercent,omitempty"`
+	Nonce         *uint64          `json:"nonce"`
+	Signature     string           `json:"signature"`
+}
+
+func (t *Transaction) MarshalJSON() ([]byte, error) {
+	ver, nonce := t.Version(), t.Nonce()
+	j := jsonTransaction{
+		Hash:      hex.EncodeToString(t.Hash()),
+		Version:   &ver,
+		Sender:    t.Sender(),
+		Nonce:     &nonce,
+		Signature: hex.EncodeToString(t.Bytes[85:149]),
+	}
+
+	switch ver {
+	case Genesis, Basic:
+		val := t.Value()
+		j.Recipient, j.Value = t.Recipient(), &val
+	case CreateSmartContract, UpdateSmartContract:
+		pfx, name, prf, fee := t.Prefix(), t.Name(), t.ProfitPercent(), t.FeePercent()
+		j.Prefix, j.Name, j.ProfitPercent, j.FeePercent = &pfx, &name, &prf, &fee
+	case UpdateProfitAddress, UpdateFeeAddress, CreateTransitAddress, DeleteTransitAddress:
+		j.Recipient = t.Recipient()
+	default:
+		return nil, ErrInvalidVersion
+	}
+
+	return json.Marshal(j)
+}
+
+func (t *Transaction) UnmarshalJSON(b []byte) error {
+	var j jsonTransaction
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&j); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	tx, err := j.transaction()
+	if err != nil {
+		return err
+	}
+
+	hsh, err := decodeHex(j.Hash, 32)
+	if err != nil || !bytes.Equal(hsh, tx.Hash()) {
+		return ErrInvalidHash
+	}
+
+	t.Bytes = tx.Bytes
+
+	return nil
+}
+
+func (j *jsonTransaction) transaction() (*Transaction, error) {
+	if j.Version == nil || j.Sender == nil || j.Nonce == nil {
+		return nil, fmt.Errorf("%w: missing version, sender or nonce", ErrInvalidJSON)
+	}
+
+	sig, err := decodeHex(j.Signature, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	t := NewTransaction()
+	t.SetVersion(*j.Version).SetSender(j.Sender).SetNonce(*j.Nonce).SetSignature(sig)
+
+	switch *j.Version {
+	case Genesis, Basic:
+		if j.Recipient == nil || j.Value == nil {
+			return nil, fmt.Errorf("%w: missing recipient or value", ErrInvalidJSON)
+		}
+
+		if j.Prefix != nil || j.Name != nil || j.ProfitPercent != nil || j.FeePercent != nil {
+			return nil, fmt.Errorf("%w: unexpected structure fields", ErrInvalidJSON)
+		}
+
+		t.SetRecipient(j.Recipient).SetValue(*j.Value)
+	case CreateSmartContract, UpdateSmartContract:
+		if j.Prefix == nil || j.Name == nil || j.ProfitPercent == nil || j.FeePercent == nil {
+			return nil, fmt.Errorf("%w: missing structure fields", ErrInvalidJSON)
+		}
+
+		if j.Recipient != nil || j.Value != nil {
+			return nil, fmt.Errorf("%w: unexpected recipient or value", ErrInvalidJSON)
+		}
+
+		if _, err := util.ParsePrefix(*j.Prefix); err != nil {
+			return nil, ErrInvalidPrefix
+		}
+
+		t.SetPrefix(*j.Prefix).SetName(*j.Name).SetProfitPercent(*j.ProfitPercent).SetFeePercent(*j.FeePercent)
+
+		if t.Name() != *j.Name {
+			return nil, fmt.Errorf("%w: name can not be encoded", ErrInvalidJSON)
+		}
+	case UpdateProfitAddress, UpdateFeeAddress, CreateTransitAddress, DeleteTransitAddress:
+		if j.Recipient == nil {
+			return nil, fmt.Errorf("%w: missing recipient", ErrInvalidJSON)
+		}
+
+		if j.Value != nil || j.Prefix != nil || j.Name != nil || j.ProfitPercent != nil || j.FeePercent != nil {
+			return nil, fmt.Errorf("%w: unexpected fields", ErrInvalidJSON)
+		}
+
+		t.SetRecipient(j.Recipient)
+	default:
+		return nil, ErrInvalidVersion
+	}
+
+	return t, nil
+}
+
+func decodeHex(s string, n int) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) != n {
+		return nil, fmt.Errorf("expected %d bytes, got %d", n, len(b))
+	}
+
+	return b, nil
+}
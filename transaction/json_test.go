@@ -0,0 +1,157 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package transaction_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/transaction"
+)
+
+func newKey() *key.SecretKey {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	return key.NewSecretKey(sec)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	sec := newKey()
+	snd := address.FromKey(sec)
+	rcp := address.FromKey(newKey())
+
+	cases := []struct {
+		desc string
+		tx   *transaction.Transaction
+	}{
+		{
+			desc: "basic",
+			tx:   transaction.NewTransaction().SetSender(snd).SetRecipient(rcp).SetValue(42).SetNonce(1),
+		},
+		{
+			desc: "create smart contract",
+			tx: transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).
+				SetSender(snd).SetPrefix("aaa").
+				SetProfitPercent(100).SetFeePercent(2000).SetNonce(2),
+		},
+		{
+			desc: "update fee address",
+			tx: transaction.NewTransaction().SetVersion(transaction.UpdateFeeAddress).
+				SetSender(snd).SetRecipient(rcp).SetNonce(3),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.tx.Sign(*sec)
+
+			b, err := json.Marshal(tc.tx)
+			if err != nil {
+				t.Fatalf("Expected: nil, got: %v", err)
+			}
+
+			act := &transaction.Transaction{}
+			if err := json.Unmarshal(b, act); err != nil {
+				t.Fatalf("Expected: nil, got: %v", err)
+			}
+
+			if !bytes.Equal(tc.tx.Bytes, act.Bytes) {
+				t.Fatalf("Expected: %x, got: %x", tc.tx.Bytes, act.Bytes)
+			}
+		})
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	tx := transaction.NewTransaction().SetVersion(transaction.UpdateSmartContract).
+		SetSender(address.FromKey(newKey())).SetPrefix("zzz")
+
+	b, _ := json.Marshal(tx)
+
+	for _, f := range []string{`"prefix":"zzz"`, `"name":""`, `"profitPercent":0`, `"feePercent":0`} {
+		if !strings.Contains(string(b), f) {
+			t.Fatalf("Expected %s in %s", f, b)
+		}
+	}
+
+	for _, f := range []string{`"recipient"`, `"value"`} {
+		if strings.Contains(string(b), f) {
+			t.Fatalf("Unexpected %s in %s", f, b)
+		}
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	sec := newKey()
+	tx := transaction.NewTransaction().SetSender(address.FromKey(sec)).
+		SetRecipient(address.FromKey(newKey())).SetValue(1).Sign(*sec)
+	b, _ := json.Marshal(tx)
+
+	var m map[string]interface{}
+	_ = json.Unmarshal(b, &m)
+
+	cases := []struct {
+		desc string
+		edit func(map[string]interface{})
+		err  error
+	}{
+		{"tampered value", func(m map[string]interface{}) { m["value"] = 2 }, transaction.ErrInvalidHash},
+		{"bad hash", func(m map[string]interface{}) { m["hash"] = "zz" }, transaction.ErrInvalidHash},
+		{"unknown field", func(m map[string]interface{}) { m["foo"] = 1 }, transaction.ErrInvalidJSON},
+		{"unexpected field", func(m map[string]interface{}) { m["prefix"] = "aaa" }, transaction.ErrInvalidJSON},
+		{"missing field", func(m map[string]interface{}) { delete(m, "recipient") }, transaction.ErrInvalidJSON},
+		{"bad version", func(m map[string]interface{}) { m["version"] = 99 }, transaction.ErrInvalidVersion},
+		{"bad signature", func(m map[string]interface{}) { m["signature"] = "00" }, transaction.ErrInvalidSignature},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			c := make(map[string]interface{})
+			for k, v := range m {
+				c[k] = v
+			}
+
+			tc.edit(c)
+			b, _ := json.Marshal(c)
+
+			err := json.Unmarshal(b, &transaction.Transaction{})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+
+	tx = transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).
+		SetSender(address.FromKey(sec)).SetPrefix("aaa").Sign(*sec)
+	b, _ = json.Marshal(tx)
+	b = []byte(strings.Replace(string(b), `"name":""`, `"name":"Shop"`, 1))
+
+	if err := json.Unmarshal(b, &transaction.Transaction{}); !errors.Is(err, transaction.ErrInvalidJSON) {
+		t.Fatalf("Expected: %v, got: %v", transaction.ErrInvalidJSON, err)
+	}
+}
@@ -27,7 +27,9 @@ import (
 	"errors"
 
 	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
 	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/network"
 	"github.com/umi-top/umi-core/util"
 )
 
@@ -75,6 +77,11 @@ func (t *Transaction) FeePercent() uint16 {
 	return binary.BigEndian.Uint16(t.Bytes[39:41])
 }
 
+func (t *Transaction) SetFeePercent(v uint16) *Transaction {
+	binary.BigEndian.PutUint16(t.Bytes[39:41], v)
+	return t
+}
+
 func (t *Transaction) Hash() []byte {
 	h := sha256.New()
 	_, _ = h.Write(t.Bytes)
@@ -103,11 +110,27 @@ func (t *Transaction) Prefix() string {
 	return util.VersionToPrefix(binary.BigEndian.Uint16(t.Bytes[35:37]))
 }
 
+// SetPrefix leaves t unchanged if p is not a valid prefix, use SetPrefixE
+// to detect it.
 func (t *Transaction) SetPrefix(p string) *Transaction {
-	binary.BigEndian.PutUint16(t.Bytes[35:37], util.PrefixToVersion(p))
+	_ = t.SetPrefixE(p)
+
 	return t
 }
 
+// SetPrefixE is like SetPrefix but returns util.ErrInvalidPrefix for an
+// invalid p.
+func (t *Transaction) SetPrefixE(p string) error {
+	v, err := util.ParsePrefix(p)
+	if err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint16(t.Bytes[35:37], v)
+
+	return nil
+}
+
 func (t *Transaction) ProfitPercent() uint16 {
 	return binary.BigEndian.Uint16(t.Bytes[37:39])
 }
@@ -165,6 +188,15 @@ func (t *Transaction) SetValue(v uint64) *Transaction {
 	return t
 }
 
+// Amount returns the value of t in the smallest units.
+func (t *Transaction) Amount() amount.Amount {
+	return amount.Amount(t.Value())
+}
+
+func (t *Transaction) SetAmount(v amount.Amount) *Transaction {
+	return t.SetValue(uint64(v))
+}
+
 func (t *Transaction) Sign(k key.SecretKey) *Transaction {
 	sig := k.Sign(t.Bytes[0:85])
 	copy(t.Bytes[85:], sig)
@@ -172,6 +204,26 @@ func (t *Transaction) Sign(k key.SecretKey) *Transaction {
 	return t
 }
 
+// SignWith signs t with s, the sender public key must be the key of s.
+// On error t is left unchanged.
+func (t *Transaction) SignWith(s key.Signer) error {
+	msg := make([]byte, 85)
+	copy(msg, t.Bytes[0:85])
+
+	sig, err := s.Sign(msg)
+	if err != nil {
+		return err
+	}
+
+	if len(sig) != 64 {
+		return ErrInvalidSignature
+	}
+
+	copy(t.Bytes[85:149], sig)
+
+	return nil
+}
+
 func (t *Transaction) ToBytes() []byte {
 	b := make([]byte, Length)
 	copy(b, t.Bytes)
@@ -179,36 +231,43 @@ func (t *Transaction) ToBytes() []byte {
 	return b
 }
 
+// Verify checks t against the mainnet parameters.
 func (t *Transaction) Verify() error {
+	return t.VerifyWithParams(network.Mainnet())
+}
+
+// VerifyWithParams checks t against the value cap and percent bounds of p.
+func (t *Transaction) VerifyWithParams(p *network.Params) error {
 	if t.Version() == 0 {
 		return ErrInvalidVersion
 	}
 
 	if t.Version() == 1 {
-		if t.Value() > 90_071_992_547_409_91 {
+		if !t.Amount().Valid() || t.Amount() > p.MaxValue {
 			return ErrInvalidValue
 		}
 
-		if bytes.Equal(t.Sender().Bytes, t.Recipient().Bytes) {
+		if bytes.Equal(t.Sender().Bytes, t.Recipient().Bytes) || t.Recipient().Verify() != nil {
 			return ErrInvalidRecipient
 		}
 	}
 
 	if t.Version() == 2 || t.Version() == 3 {
-		if t.Prefix() == "umi" {
+		pfx, err := util.ParseVersion(binary.BigEndian.Uint16(t.Bytes[35:37]))
+		if err != nil || util.IsSystemPrefix(pfx) {
 			return ErrInvalidPrefix
 		}
 
-		if t.ProfitPercent() > 500 || t.ProfitPercent() < 100 {
+		if t.ProfitPercent() > p.MaxProfitPercent || t.ProfitPercent() < p.MinProfitPercent {
 			return ErrInvalidProfitPercent
 		}
 
-		if t.FeePercent() > 2000 {
+		if t.FeePercent() > p.MaxFeePercent {
 			return ErrInvalidFeePercent
 		}
 	}
 
-	if !t.Sender().PublicKey().VerifySignature(t.Bytes[0:85], t.Bytes[85:149]) {
+	if !t.Sender().PublicKey().VerifySignature(t.Bytes[85:149], t.Bytes[0:85]) {
 		return ErrInvalidSignature
 	}
 
@@ -213,3 +213,124 @@ package transaction_test
 //		t.Error("Expected not nil")
 //	}
 //}
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/network"
+	"github.com/umi-top/umi-core/transaction"
+	"github.com/umi-top/umi-core/util"
+)
+
+func TestVerifySignature(t *testing.T) {
+	sec := newKey()
+	tx := transaction.NewTransaction().SetVersion(transaction.Basic).
+		SetSender(address.FromKey(sec)).SetRecipient(address.FromKey(newKey())).SetValue(1).Sign(*sec)
+
+	if err := tx.Verify(); err != nil {
+		t.Fatalf("Expected: %v, got: %v", nil, err)
+	}
+
+	tx.Bytes[84] ^= 1
+
+	if err := tx.Verify(); !errors.Is(err, transaction.ErrInvalidSignature) {
+		t.Fatalf("Expected: %v, got: %v", transaction.ErrInvalidSignature, err)
+	}
+}
+
+func TestVerifyPrefix(t *testing.T) {
+	sec := newKey()
+
+	cases := []struct {
+		prefix string
+		err    error
+	}{
+		{"aaa", nil},
+		{"umi", transaction.ErrInvalidPrefix},
+		{"genesis", transaction.ErrInvalidPrefix},
+		{"aa", transaction.ErrInvalidPrefix},
+		{"AAA", transaction.ErrInvalidPrefix},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.prefix, func(t *testing.T) {
+			tx := transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).
+				SetSender(address.FromKey(sec)).SetPrefix(tc.prefix).SetProfitPercent(100).Sign(*sec)
+
+			if err := tx.Verify(); !errors.Is(err, tc.err) {
+				t.Fatalf("Expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestVerifyWithParams(t *testing.T) {
+	sec := newKey()
+	snd := address.FromKey(sec)
+	rcp := address.FromKey(newKey())
+
+	p, _ := network.NewDevnet("local").SetMaxValue(1000).SetProfitPercent(0, 10000).SetMaxFeePercent(0).Build()
+
+	basic := func(v uint64) *transaction.Transaction {
+		return transaction.NewTransaction().SetSender(snd).SetRecipient(rcp).SetValue(v).Sign(*sec)
+	}
+
+	create := func(profit, fee uint16) *transaction.Transaction {
+		return transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).SetSender(snd).
+			SetPrefix("aaa").SetProfitPercent(profit).SetFeePercent(fee).Sign(*sec)
+	}
+
+	cases := []struct {
+		desc      string
+		tx        *transaction.Transaction
+		main, dev error
+	}{
+		{"small value", basic(1000), nil, nil},
+		{"large value", basic(1001), nil, transaction.ErrInvalidValue},
+		{"low profit", create(0, 0), transaction.ErrInvalidProfitPercent, nil},
+		{"high profit", create(10000, 0), transaction.ErrInvalidProfitPercent, nil},
+		{"fee", create(100, 1), nil, transaction.ErrInvalidFeePercent},
+	}
+
+	for _, tc := range cases {
+		if err := tc.tx.Verify(); !errors.Is(err, tc.main) {
+			t.Fatalf("%s: mainnet: Expected: %v, got: %v", tc.desc, tc.main, err)
+		}
+
+		if err := tc.tx.VerifyWithParams(p); !errors.Is(err, tc.dev) {
+			t.Fatalf("%s: devnet: Expected: %v, got: %v", tc.desc, tc.dev, err)
+		}
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tx := transaction.NewTransaction().SetAmount(amount.Max)
+
+	if act := tx.Value(); act != uint64(amount.Max) {
+		t.Fatalf("Expected: %d, got: %d", uint64(amount.Max), act)
+	}
+
+	if act := tx.SetValue(12345).Amount(); act != 12345 {
+		t.Fatalf("Expected: 123.45, got: %s", act)
+	}
+}
+
+func TestSetPrefixE(t *testing.T) {
+	tx := transaction.NewTransaction().SetVersion(transaction.CreateSmartContract).SetPrefix("aaa")
+
+	if err := tx.SetPrefixE("AAA"); !errors.Is(err, util.ErrInvalidPrefix) {
+		t.Fatalf("Expected: %v, got: %v", util.ErrInvalidPrefix, err)
+	}
+
+	if act := tx.SetPrefix("aa").Prefix(); act != "aaa" {
+		t.Fatalf("Expected: aaa, got: %s", act)
+	}
+
+	if err := tx.SetPrefixE("zzz"); err != nil || tx.Prefix() != "zzz" {
+		t.Fatalf("Expected: zzz, got: %s %v", tx.Prefix(), err)
+	}
+}
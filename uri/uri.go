@@ -0,0 +1,183 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package uri builds and parses payment requests of the form
+//
+//	umi:umi1...?amount=12.34&label=Shop&message=Order%2042&exp=1600000000
+//
+// Amounts are written in UMI with at most two fractional digits and are
+// kept in base units (1 UMI = 100 base units) to avoid rounding.
+package uri
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/util"
+)
+
+const Scheme = "umi"
+
+var (
+	ErrInvalidURI       = errors.New("uri: invalid uri")
+	ErrInvalidAddress   = errors.New("uri: invalid address")
+	ErrInvalidAmount    = errors.New("uri: invalid amount")
+	ErrInvalidExpiry    = errors.New("uri: invalid expiry")
+	ErrUnsupportedParam = errors.New("uri: unsupported required parameter")
+)
+
+// addressError is ErrInvalidAddress that keeps the address error, so an
+// *address.TypoError is still found by errors.As. Its positions are
+// indexes into the address part of the URI.
+type addressError struct {
+	err error
+}
+
+func (e *addressError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrInvalidAddress, e.err)
+}
+
+func (e *addressError) Unwrap() error {
+	return e.err
+}
+
+func (e *addressError) Is(target error) bool {
+	return target == ErrInvalidAddress
+}
+
+type PaymentRequest struct {
+	Address *address.Address
+	Amount  amount.Amount
+	Label   string
+	Message string
+	Expires time.Time
+}
+
+func Parse(s string) (*PaymentRequest, error) {
+	if len(s) <= len(Scheme)+1 || !strings.EqualFold(s[:len(Scheme)+1], Scheme+":") {
+		return nil, ErrInvalidURI
+	}
+
+	s = s[len(Scheme)+1:]
+	raw := ""
+
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		s, raw = s[:i], s[i+1:]
+	}
+
+	adr, err := address.ParseBech32(s)
+	if err != nil {
+		return nil, &addressError{err: err}
+	}
+
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURI, err)
+	}
+
+	r := &PaymentRequest{Address: adr}
+
+	for k, v := range q {
+		if len(v) != 1 {
+			return nil, fmt.Errorf("%w: duplicate parameter %s", ErrInvalidURI, k)
+		}
+
+		switch k {
+		case "amount":
+			if r.Amount, err = amount.Parse(v[0]); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+			}
+		case "label":
+			r.Label = v[0]
+		case "message":
+			r.Message = v[0]
+		case "exp":
+			sec, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil || sec <= 0 {
+				return nil, ErrInvalidExpiry
+			}
+
+			r.Expires = time.Unix(sec, 0)
+		default:
+			if strings.HasPrefix(k, "req-") {
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedParam, k)
+			}
+		}
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *PaymentRequest) Validate() error {
+	if r.Address == nil || r.Address.Verify() != nil || r.Address.Prefix() == util.GenesisPrefix {
+		return ErrInvalidAddress
+	}
+
+	if !r.Amount.Valid() {
+		return ErrInvalidAmount
+	}
+
+	return nil
+}
+
+func (r *PaymentRequest) Expired(now time.Time) bool {
+	return !r.Expires.IsZero() && !now.Before(r.Expires)
+}
+
+func (r *PaymentRequest) String() string {
+	var q []string
+
+	if r.Amount > 0 {
+		q = append(q, "amount="+strings.TrimSuffix(strings.TrimRight(r.Amount.String(), "0"), "."))
+	}
+
+	if r.Label != "" {
+		q = append(q, "label="+escape(r.Label))
+	}
+
+	if r.Message != "" {
+		q = append(q, "message="+escape(r.Message))
+	}
+
+	if !r.Expires.IsZero() {
+		q = append(q, "exp="+strconv.FormatInt(r.Expires.Unix(), 10))
+	}
+
+	s := Scheme + ":" + r.Address.ToBech32()
+	if len(q) > 0 {
+		s += "?" + strings.Join(q, "&")
+	}
+
+	return s
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
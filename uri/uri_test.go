@@ -0,0 +1,133 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package uri_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/amount"
+	"github.com/umi-top/umi-core/uri"
+)
+
+const bech = "umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhg"
+
+func TestString(t *testing.T) {
+	r := &uri.PaymentRequest{
+		Address: address.FromBech32(bech),
+		Amount:  1230,
+		Label:   "Coffee & Co",
+		Message: "Order #42",
+		Expires: time.Unix(1600000000, 0),
+	}
+
+	exp := "umi:" + bech + "?amount=12.3&label=Coffee%20%26%20Co&message=Order%20%2342&exp=1600000000"
+	if act := r.String(); act != exp {
+		t.Fatalf("Expected: %s, got: %s", exp, act)
+	}
+
+	if act := (&uri.PaymentRequest{Address: address.FromBech32(bech)}).String(); act != "umi:"+bech {
+		t.Fatalf("Expected: %s, got: %s", "umi:"+bech, act)
+	}
+}
+
+func TestParse(t *testing.T) {
+	r, err := uri.Parse("UMI:" + bech + "?amount=0.05&label=a+b&message=%D0%BF%D1%80%D0%B8%D0%B2%D0%B5%D1%82&exp=1600000000&foo=bar")
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if r.Address.ToBech32() != bech || r.Amount != 5 || r.Label != "a b" || r.Message != "привет" {
+		t.Fatalf("Unexpected: %+v", r)
+	}
+
+	if !r.Expired(time.Unix(1600000000, 0)) || r.Expired(time.Unix(1599999999, 0)) {
+		t.Fatal("Unexpected expiry")
+	}
+}
+
+func TestAmountRoundTrip(t *testing.T) {
+	cases := map[string]amount.Amount{
+		"0.01":              1,
+		"0.1":               10,
+		"1":                 100,
+		"12.34":             1234,
+		"90071992547409.91": 9007199254740991,
+	}
+
+	for s, v := range cases {
+		r, err := uri.Parse("umi:" + bech + "?amount=" + s)
+		if err != nil || r.Amount != v {
+			t.Fatalf("For %s expected: %d, got: %v (%v)", s, v, r, err)
+		}
+
+		if act, _ := uri.Parse(r.String()); act.Amount != v {
+			t.Fatalf("For %s expected: %d, got: %d", s, v, act.Amount)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := []struct {
+		str string
+		err error
+	}{
+		{"bitcoin:" + bech, uri.ErrInvalidURI},
+		{"umi:", uri.ErrInvalidURI},
+		{"umi:" + bech[:20] + "x" + bech[21:], uri.ErrInvalidAddress},
+		{"umi:" + address.FromBech32(bech).SetVersion(address.Genesis).ToBech32(), uri.ErrInvalidAddress},
+		{"umi:" + bech + "?amount=1.234", uri.ErrInvalidAmount},
+		{"umi:" + bech + "?amount=-1", uri.ErrInvalidAmount},
+		{"umi:" + bech + "?amount=1e3", uri.ErrInvalidAmount},
+		{"umi:" + bech + "?amount=.5", uri.ErrInvalidAmount},
+		{"umi:" + bech + "?amount=1.", uri.ErrInvalidAmount},
+		{"umi:" + bech + "?amount=90071992547409.92", uri.ErrInvalidAmount},
+		{"umi:" + bech + "?amount=1&amount=2", uri.ErrInvalidURI},
+		{"umi:" + bech + "?exp=soon", uri.ErrInvalidExpiry},
+		{"umi:" + bech + "?req-fee=1", uri.ErrUnsupportedParam},
+	}
+
+	for _, tc := range cases {
+		if _, err := uri.Parse(tc.str); !errors.Is(err, tc.err) {
+			t.Errorf("For %s expected: %v, got: %v", tc.str, tc.err, err)
+		}
+	}
+}
+
+func TestParseTypo(t *testing.T) {
+	typo := bech[:20] + "x" + bech[21:]
+
+	_, err := uri.Parse("umi:" + typo)
+	if !errors.Is(err, uri.ErrInvalidAddress) || !errors.Is(err, address.ErrInvalidAddress) {
+		t.Fatalf("Expected: %v, got: %v", uri.ErrInvalidAddress, err)
+	}
+
+	var te *address.TypoError
+	if !errors.As(err, &te) {
+		t.Fatalf("Expected: TypoError, got: %v", err)
+	}
+
+	if len(te.Positions) != 1 || te.Positions[0] != 20 || te.Suggestion != bech {
+		t.Fatalf("Expected: [20] %s, got: %v %s", bech, te.Positions, te.Suggestion)
+	}
+}
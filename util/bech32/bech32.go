@@ -22,61 +22,250 @@ package bech32
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/umi-top/umi-core/util"
 )
 
-const alphabet = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+// Alphabet is the bech32 character set, the index of a character is its value.
+const Alphabet = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// MaxLength is the maximum length of a bech32 string as defined by BIP 173.
+const MaxLength = 90
 
 var generator = []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
 
-func Encode(b []byte) string {
-	ver := binary.BigEndian.Uint16(b[0:2])
-	wrd, _ := ConvertBits(b[2:], 8, 5, true)
-	adr, _ := EncodeRef(util.VersionToPrefix(ver), wrd)
+// Variant selects the checksum constant, see BIP 173 and BIP 350.
+type Variant int
+
+const (
+	Bech32 Variant = iota + 1
+	Bech32m
+)
+
+func (v Variant) String() string {
+	switch v {
+	case Bech32:
+		return "bech32"
+	case Bech32m:
+		return "bech32m"
+	default:
+		return "unknown"
+	}
+}
+
+func (v Variant) constant() int {
+	if v == Bech32m {
+		return 0x2bc830a3
+	}
+
+	return 1
+}
+
+var (
+	ErrInvalidLength    = errors.New("bech32: invalid length")
+	ErrInvalidHRP       = errors.New("bech32: invalid human-readable part")
+	ErrInvalidSeparator = errors.New("bech32: invalid separator index")
+	ErrMixedCase        = errors.New("bech32: mixed case")
+	ErrInvalidCharacter = errors.New("bech32: invalid character")
+	ErrInvalidChecksum  = errors.New("bech32: invalid checksum")
+	ErrInvalidPadding   = errors.New("bech32: invalid padding")
+	ErrInvalidVariant   = errors.New("bech32: invalid variant")
+)
+
+// InvalidCharError reports a character at Pos (0-based, in the original
+// string) that is out of range or not part of the alphabet.
+type InvalidCharError struct {
+	Pos  int
+	Char byte
+}
+
+func (e *InvalidCharError) Error() string {
+	return fmt.Sprintf("bech32: invalid character %q at position %d", e.Char, e.Pos)
+}
 
-	return adr
+func (e *InvalidCharError) Unwrap() error {
+	return ErrInvalidCharacter
 }
 
+// ChecksumError reports a checksum mismatch, Expected holds the checksum
+// of the given data for the accepted variant closest to the input.
+type ChecksumError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("bech32: invalid checksum, expected %s, got %s", e.Expected, e.Actual)
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return ErrInvalidChecksum
+}
+
+// Encode encodes a 34-byte address: the version becomes the prefix and
+// the public key becomes the data part.
+func Encode(b []byte) string {
+	s, _ := EncodeBytes(util.VersionToPrefix(binary.BigEndian.Uint16(b[0:2])), b[2:34], Bech32)
+	return s
+}
+
+// Decode decodes a bech32 address into its 34-byte form.
 func Decode(s string) ([]byte, error) {
-	pfx, wrd, err := DecodeRef(s)
+	pfx, pub, ver, err := DecodeBytes(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if ver != Bech32 {
+		return nil, ErrInvalidVariant
+	}
+
+	v, err := util.ParsePrefix(pfx)
 	if err != nil {
 		return nil, err
 	}
 
-	pub, _ := ConvertBits(wrd, 5, 8, false)
+	if len(pub) != 32 {
+		return nil, fmt.Errorf("%w: data length %d", ErrInvalidLength, len(pub))
+	}
+
 	b := make([]byte, 34)
-	binary.BigEndian.PutUint16(b[0:2], util.PrefixToVersion(pfx))
+	binary.BigEndian.PutUint16(b[0:2], v)
 	copy(b[2:], pub)
 
 	return b, nil
 }
 
-// Decode decodes a bech32 encoded string, returning the human-readable
-// part and the data part excluding the checksum.
+// EncodeBytes regroups an arbitrary payload into 5-bit words and encodes it.
+func EncodeBytes(hrp string, payload []byte, v Variant) (string, error) {
+	data, err := ConvertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	return EncodeVariant(hrp, data, v)
+}
+
+// DecodeBytes is the inverse of EncodeBytes.
+func DecodeBytes(s string) (string, []byte, Variant, error) {
+	hrp, data, v, err := DecodeVariant(s)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	payload, err := ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	return hrp, payload, v, nil
+}
+
+// EncodeVariant encodes 5-bit words with the human-readable part hrp.
+func EncodeVariant(hrp string, data []byte, v Variant) (string, error) {
+	if v != Bech32 && v != Bech32m {
+		return "", ErrInvalidVariant
+	}
+
+	if len(hrp) == 0 || len(hrp)+len(data)+7 > MaxLength {
+		return "", ErrInvalidLength
+	}
+
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 || (hrp[i] >= 'A' && hrp[i] <= 'Z') {
+			return "", ErrInvalidHRP
+		}
+	}
+
+	// Calculate the checksum of the data and append it at the end.
+	combined := make([]byte, 0, len(data)+6)
+	combined = append(combined, data...)
+	combined = append(combined, checksum(hrp, data, v)...)
+
+	// The resulting bech32 string is the concatenation of the hrp, the
+	// separator 1, data and checksum. Everything after the separator is
+	// represented using the specified alphabet.
+	dataChars, err := toChars(combined)
+	if err != nil {
+		return "", err
+	}
+
+	return hrp + "1" + dataChars, nil
+}
+
+// DecodeVariant decodes a bech32 or bech32m string, returning the
+// human-readable part, the data part excluding the checksum and the
+// variant whose checksum matched.
+func DecodeVariant(bech string) (string, []byte, Variant, error) {
+	hrp, decoded, err := split(bech)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	var v Variant
+
+	switch polymod(append(hrpExpand(hrp), ints(decoded)...)) {
+	case Bech32.constant():
+		v = Bech32
+	case Bech32m.constant():
+		v = Bech32m
+	default:
+		return "", nil, 0, newChecksumError(hrp, decoded, Bech32, Bech32m)
+	}
+
+	// We exclude the last 6 bytes, which is the checksum.
+	return hrp, decoded[:len(decoded)-6], v, nil
+}
+
+// DecodeRef decodes a bech32 encoded string, returning the human-readable
+// part and the data part excluding the checksum. Bech32m strings are
+// rejected.
 func DecodeRef(bech string) (string, []byte, error) {
+	hrp, decoded, err := split(bech)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if polymod(append(hrpExpand(hrp), ints(decoded)...)) != Bech32.constant() {
+		return "", nil, newChecksumError(hrp, decoded, Bech32)
+	}
+
+	return hrp, decoded[:len(decoded)-6], nil
+}
+
+// EncodeRef encodes a byte slice into a bech32 string with the
+// human-readable part hrp. Note that the bytes must each encode 5 bits
+// (base32).
+func EncodeRef(hrp string, data []byte) (string, error) {
+	return EncodeVariant(hrp, data, Bech32)
+}
+
+// split validates the string and returns the lowercase human-readable part
+// and the 5-bit values of the data part including the checksum.
+func split(bech string) (string, []byte, error) {
 	// The maximum allowed length for a bech32 string is 90. It must also
 	// be at least 8 characters, since it needs a non-empty HRP, a
 	// separator, and a 6 character checksum.
-	if len(bech) < 8 || len(bech) > 90 {
-		return "", nil, fmt.Errorf("invalid bech32 string length %d",
-			len(bech))
+	if len(bech) < 8 || len(bech) > MaxLength {
+		return "", nil, fmt.Errorf("%w: %d", ErrInvalidLength, len(bech))
 	}
-	// Only	ASCII characters between 33 and 126 are allowed.
+
+	// Only ASCII characters between 33 and 126 are allowed.
 	for i := 0; i < len(bech); i++ {
 		if bech[i] < 33 || bech[i] > 126 {
-			return "", nil, fmt.Errorf("invalid character in string: '%c'", bech[i])
+			return "", nil, &InvalidCharError{Pos: i, Char: bech[i]}
 		}
 	}
 
 	// The characters must be either all lowercase or all uppercase.
 	lower := strings.ToLower(bech)
 	upper := strings.ToUpper(bech)
+
 	if bech != lower && bech != upper {
-		return "", nil, fmt.Errorf("string not all lowercase or all " +
-			"uppercase")
+		return "", nil, ErrMixedCase
 	}
 
 	// We'll work with the lowercase string from now on.
@@ -84,85 +273,70 @@ func DecodeRef(bech string) (string, []byte, error) {
 
 	// The string is invalid if the last '1' is non-existent, it is the
 	// first character of the string (no human-readable part) or one of the
-	// last 6 characters of the string (since checksum cannot contain '1'),
-	// or if the string is more than 90 characters in total.
+	// last 6 characters of the string (since checksum cannot contain '1').
 	one := strings.LastIndexByte(bech, '1')
 	if one < 1 || one+7 > len(bech) {
-		return "", nil, fmt.Errorf("invalid index of 1")
+		return "", nil, ErrInvalidSeparator
 	}
 
-	// The human-readable part is everything before the last '1'.
-	hrp := bech[:one]
-	data := bech[one+1:]
-
 	// Each character corresponds to the byte with value of the index in
-	// 'alphabet'.
-	decoded, err := toBytes(data)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed converting data to bytes: "+
-			"%v", err)
-	}
-
-	if !bech32VerifyChecksum(hrp, decoded) {
-		moreInfo := ""
-		checksum := bech[len(bech)-6:]
-		expected, err := toChars(bech32Checksum(hrp,
-			decoded[:len(decoded)-6]))
-		if err == nil {
-			moreInfo = fmt.Sprintf("Expected %v, got %v.",
-				expected, checksum)
+	// 'Alphabet'.
+	decoded := make([]byte, 0, len(bech)-one-1)
+
+	for i := one + 1; i < len(bech); i++ {
+		index := strings.IndexByte(Alphabet, bech[i])
+		if index < 0 {
+			return "", nil, &InvalidCharError{Pos: i, Char: bech[i]}
 		}
-		return "", nil, fmt.Errorf("checksum failed. " + moreInfo)
+
+		decoded = append(decoded, byte(index))
 	}
 
-	// We exclude the last 6 bytes, which is the checksum.
-	return hrp, decoded[:len(decoded)-6], nil
+	return bech[:one], decoded, nil
 }
 
-// Encode encodes a byte slice into a bech32 string with the
-// human-readable part hrb. Note that the bytes must each encode 5 bits
-// (base32).
-func EncodeRef(hrp string, data []byte) (string, error) {
-	// Calculate the checksum of the data and append it at the end.
-	checksum := bech32Checksum(hrp, data)
-	combined := append(data, checksum...)
+// newChecksumError reports the checksum of the variant, out of the accepted
+// ones, that differs from the input in the fewest characters.
+func newChecksumError(hrp string, decoded []byte, accepted ...Variant) error {
+	data, sum := decoded[:len(decoded)-6], decoded[len(decoded)-6:]
 
-	// The resulting bech32 string is the concatenation of the hrp, the
-	// separator 1, data and checksum. Everything after the separator is
-	// represented using the specified alphabet.
-	dataChars, err := toChars(combined)
-	if err != nil {
-		return "", fmt.Errorf("unable to convert data bytes to chars: "+
-			"%v", err)
+	var best []byte
+
+	for _, v := range accepted {
+		if c := checksum(hrp, data, v); best == nil || distance(c, sum) < distance(best, sum) {
+			best = c
+		}
 	}
-	return hrp + "1" + dataChars, nil
+
+	expected, _ := toChars(best)
+	actual, _ := toChars(sum)
+
+	return &ChecksumError{Expected: expected, Actual: actual}
 }
 
-// toBytes converts each character in the string 'chars' to the value of the
-// index of the corresponding character in 'alphabet'.
-func toBytes(chars string) ([]byte, error) {
-	decoded := make([]byte, 0, len(chars))
-	for i := 0; i < len(chars); i++ {
-		index := strings.IndexByte(alphabet, chars[i])
-		if index < 0 {
-			return nil, fmt.Errorf("invalid character not part of "+
-				"alphabet: %v", chars[i])
+func distance(a, b []byte) (n int) {
+	for i := range a {
+		if a[i] != b[i] {
+			n++
 		}
-		decoded = append(decoded, byte(index))
 	}
-	return decoded, nil
+
+	return n
 }
 
 // toChars converts the byte slice 'data' to a string where each byte in 'data'
-// encodes the index of a character in 'alphabet'.
+// encodes the index of a character in 'Alphabet'.
 func toChars(data []byte) (string, error) {
 	result := make([]byte, 0, len(data))
+
 	for _, b := range data {
-		if int(b) >= len(alphabet) {
-			return "", fmt.Errorf("invalid data byte: %v", b)
+		if int(b) >= len(Alphabet) {
+			return "", fmt.Errorf("%w: data byte %d", ErrInvalidCharacter, b)
 		}
-		result = append(result, alphabet[b])
+
+		result = append(result, Alphabet[b])
 	}
+
 	return string(result), nil
 }
 
@@ -177,14 +351,13 @@ func ConvertBits(data []byte, fromBits, toBits uint8, pad bool) ([]byte, error)
 	var regrouped []byte
 
 	// Keep track of the next byte we create and how many bits we have
-	// added to it out of the toBits goal.
+	// added to it out of the toBits goal.
 	nextByte := byte(0)
 	filledBits := uint8(0)
 
 	for _, b := range data {
-
 		// Discard unused bits.
-		b = b << (8 - fromBits)
+		b <<= 8 - fromBits
 
 		// How many bits remaining to extract from the input data.
 		remFromBits := fromBits
@@ -205,12 +378,12 @@ func ConvertBits(data []byte, fromBits, toBits uint8, pad bool) ([]byte, error)
 
 			// Discard the bits we just extracted and get ready for
 			// next iteration.
-			b = b << toExtract
+			b <<= toExtract
 			remFromBits -= toExtract
 			filledBits += toExtract
 
 			// If the nextByte is completely filled, we add it to
-			// our regrouped bytes and start on the next byte.
+			// our regrouped bytes and start on the next byte.
 			if filledBits == toBits {
 				regrouped = append(regrouped, nextByte)
 				filledBits = 0
@@ -221,7 +394,7 @@ func ConvertBits(data []byte, fromBits, toBits uint8, pad bool) ([]byte, error)
 
 	// We pad any unfinished group if specified.
 	if pad && filledBits > 0 {
-		nextByte = nextByte << (toBits - filledBits)
+		nextByte <<= toBits - filledBits
 		regrouped = append(regrouped, nextByte)
 		filledBits = 0
 		nextByte = 0
@@ -229,64 +402,67 @@ func ConvertBits(data []byte, fromBits, toBits uint8, pad bool) ([]byte, error)
 
 	// Any incomplete group must be <= 4 bits, and all zeroes.
 	if filledBits > 0 && (filledBits > 4 || nextByte != 0) {
-		return nil, fmt.Errorf("invalid incomplete group")
+		return nil, ErrInvalidPadding
 	}
 
 	return regrouped, nil
 }
 
-// For more details on the checksum calculation, please refer to BIP 173.
-func bech32Checksum(hrp string, data []byte) []byte {
-	// Convert the bytes to list of integers, as this is needed for the
-	// checksum calculation.
-	integers := make([]int, len(data))
-	for i, b := range data {
-		integers[i] = int(b)
-	}
-	values := append(bech32HrpExpand(hrp), integers...)
+// For more details on the checksum calculation, please refer to BIP 173
+// and BIP 350.
+func checksum(hrp string, data []byte, v Variant) []byte {
+	values := append(hrpExpand(hrp), ints(data)...)
 	values = append(values, []int{0, 0, 0, 0, 0, 0}...)
-	polymod := bech32Polymod(values) ^ 1
-	var res []byte
+	mod := polymod(values) ^ v.constant()
+
+	res := make([]byte, 6)
 	for i := 0; i < 6; i++ {
-		res = append(res, byte((polymod>>uint(5*(5-i)))&31))
+		res[i] = byte((mod >> uint(5*(5-i))) & 31)
 	}
+
 	return res
 }
 
 // For more details on the polymod calculation, please refer to BIP 173.
-func bech32Polymod(values []int) int {
+func polymod(values []int) int {
 	chk := 1
+
 	for _, v := range values {
 		b := chk >> 25
 		chk = (chk&0x1ffffff)<<5 ^ v
+
 		for i := 0; i < 5; i++ {
 			if (b>>uint(i))&1 == 1 {
 				chk ^= generator[i]
 			}
 		}
 	}
+
 	return chk
 }
 
 // For more details on HRP expansion, please refer to BIP 173.
-func bech32HrpExpand(hrp string) []int {
+func hrpExpand(hrp string) []int {
 	v := make([]int, 0, len(hrp)*2+1)
+
 	for i := 0; i < len(hrp); i++ {
 		v = append(v, int(hrp[i]>>5))
 	}
+
 	v = append(v, 0)
+
 	for i := 0; i < len(hrp); i++ {
 		v = append(v, int(hrp[i]&31))
 	}
+
 	return v
 }
 
-// For more details on the checksum verification, please refer to BIP 173.
-func bech32VerifyChecksum(hrp string, data []byte) bool {
-	integers := make([]int, len(data))
+func ints(data []byte) []int {
+	v := make([]int, len(data))
 	for i, b := range data {
-		integers[i] = int(b)
+		v[i] = int(b)
 	}
-	concat := append(bech32HrpExpand(hrp), integers...)
-	return bech32Polymod(concat) == 1
-}
\ No newline at end of file
+
+	return v
+}
@@ -21,6 +21,7 @@
 package bech32_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -37,11 +38,11 @@ func TestBech32(t *testing.T) {
 		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", true},
 		{"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j", true},
 		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", true},
-		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e2w", false},                                // invalid checksum
-		{"s lit1checkupstagehandshakeupstreamerranterredcaperredp8hs2p", false},                                // invalid character (space) in hrp
-		{"spl" + string(127) + "t1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", false},              // invalid character (DEL) in hrp
-		{"split1cheo2y9e2w", false},                                                                            // invalid character (o) in data part
-		{"split1a2y9w", false},                                                                                 // too short data part
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e2w", false},                         // invalid checksum
+		{"s lit1checkupstagehandshakeupstreamerranterredcaperredp8hs2p", false},                         // invalid character (space) in hrp
+		{"spl" + string(rune(127)) + "t1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", false}, // invalid character (DEL) in hrp
+		{"split1cheo2y9e2w", false}, // invalid character (o) in data part
+		{"split1a2y9w", false},      // too short data part
 		{"1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", false},                                     // empty hrp
 		{"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j", false}, // too long
 	}
@@ -82,3 +83,176 @@ func TestBech32(t *testing.T) {
 		}
 	}
 }
+
+func TestBech32m(t *testing.T) {
+	valid := []string{
+		"A1LQFN3A",
+		"a1lqfn3a",
+		"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6",
+		"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+		"11llllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllludsr8",
+		"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+		"?1v759aa",
+	}
+
+	for _, str := range valid {
+		hrp, data, v, err := bech32.DecodeVariant(str)
+		if err != nil {
+			t.Errorf("expected %s to be valid bech32m: %v", str, err)
+			continue
+		}
+
+		if v != bech32.Bech32m {
+			t.Errorf("expected %s to be bech32m, got %v", str, v)
+		}
+
+		encoded, err := bech32.EncodeVariant(hrp, data, bech32.Bech32m)
+		if err != nil || encoded != strings.ToLower(str) {
+			t.Errorf("expected %s to encode to itself, got %s (%v)", str, encoded, err)
+		}
+
+		// Bech32m strings must not be accepted as bech32.
+		if _, _, err := bech32.DecodeRef(str); !errors.Is(err, bech32.ErrInvalidChecksum) {
+			t.Errorf("expected %s to fail bech32 decoding, got %v", str, err)
+		}
+	}
+
+	invalid := []string{
+		string(rune(0x20)) + "1xj0phk",
+		string(rune(0x7f)) + "1g6xzxy",
+		string(rune(0x80)) + "1vctc34",
+		"an84characterslonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11d6pts4",
+		"qyrz8wqd2c9m",
+		"1qyrz8wqd2c9m",
+		"y1b0jsk6g",
+		"lt1igcx5c0",
+		"in1muywd",
+		"mm1crxm3i",
+		"au1s5cgom",
+		"M1VUXWEZ",
+		"16plkw9",
+		"1p2gdwpf",
+	}
+
+	for _, str := range invalid {
+		if _, _, _, err := bech32.DecodeVariant(str); err == nil {
+			t.Errorf("expected decoding to fail for invalid string %q", str)
+		}
+	}
+}
+
+func TestBech32Vectors(t *testing.T) {
+	valid := []string{"?1ezyfcl", "a12uel5l"}
+
+	for _, str := range valid {
+		if _, _, v, err := bech32.DecodeVariant(str); err != nil || v != bech32.Bech32 {
+			t.Errorf("expected %s to be valid bech32, got %v (%v)", str, v, err)
+		}
+	}
+
+	invalid := []string{
+		string(rune(0x20)) + "1nwldj5",
+		string(rune(0x7f)) + "1axkwrx",
+		string(rune(0x80)) + "1eym55h",
+		"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx",
+		"pzry9x0s0muk",
+		"1pzry9x0s0muk",
+		"x1b4n0q5v",
+		"li1dgmt3",
+		"de1lg7wt" + string(rune(0xff)),
+		"A1G7SGD8",
+		"10a06t8",
+		"1qzzfhee",
+	}
+
+	for _, str := range invalid {
+		if _, _, _, err := bech32.DecodeVariant(str); err == nil {
+			t.Errorf("expected decoding to fail for invalid string %q", str)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		str string
+		err error
+	}{
+		{"A12uEL5L", bech32.ErrMixedCase},
+		{"a12uel5", bech32.ErrInvalidLength},
+		{"pzry9x0s0muk", bech32.ErrInvalidSeparator},
+		{"a12uel5m", bech32.ErrInvalidChecksum},
+	}
+
+	for _, tc := range cases {
+		if _, _, _, err := bech32.DecodeVariant(tc.str); !errors.Is(err, tc.err) {
+			t.Errorf("for %s expected %v, got %v", tc.str, tc.err, err)
+		}
+	}
+
+	_, _, _, err := bech32.DecodeVariant("x1b4n0q5v")
+
+	var charErr *bech32.InvalidCharError
+	if !errors.As(err, &charErr) || charErr.Pos != 2 || charErr.Char != 'b' {
+		t.Errorf("expected invalid character 'b' at 2, got %v", err)
+	}
+
+	_, _, _, err = bech32.DecodeVariant("a12uel5m")
+
+	var sumErr *bech32.ChecksumError
+	if !errors.As(err, &sumErr) || sumErr.Expected != "2uel5l" || sumErr.Actual != "2uel5m" {
+		t.Errorf("expected checksum 2uel5l, got %v", err)
+	}
+
+	// a1lqfn3a is a valid bech32m string, the nearer variant is reported
+	_, _, _, err = bech32.DecodeVariant("a1lqfn3q")
+	if !errors.As(err, &sumErr) || sumErr.Expected != "lqfn3a" || sumErr.Actual != "lqfn3q" {
+		t.Errorf("expected checksum lqfn3a, got %v", err)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	payload := []byte("arbitrary payload, not an address")
+
+	for _, v := range []bech32.Variant{bech32.Bech32, bech32.Bech32m} {
+		s, err := bech32.EncodeBytes("test", payload, v)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		hrp, act, ver, err := bech32.DecodeBytes(s)
+		if err != nil || hrp != "test" || ver != v || string(act) != string(payload) {
+			t.Fatalf("expected %s %q %v, got %s %q %v (%v)", "test", payload, v, hrp, act, ver, err)
+		}
+	}
+
+	if _, err := bech32.EncodeBytes("Test", payload, bech32.Bech32); !errors.Is(err, bech32.ErrInvalidHRP) {
+		t.Fatalf("expected %v, got %v", bech32.ErrInvalidHRP, err)
+	}
+
+	if _, err := bech32.EncodeBytes("test", make([]byte, 60), bech32.Bech32); !errors.Is(err, bech32.ErrInvalidLength) {
+		t.Fatalf("expected %v, got %v", bech32.ErrInvalidLength, err)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	adr := "umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhg"
+
+	b, err := bech32.Decode(adr)
+	if err != nil || len(b) != 34 {
+		t.Fatalf("expected 34 bytes, got %x (%v)", b, err)
+	}
+
+	if act := bech32.Encode(b); act != adr {
+		t.Fatalf("expected %s, got %s", adr, act)
+	}
+
+	m, _ := bech32.EncodeBytes("umi", b[2:], bech32.Bech32m)
+	if _, err := bech32.Decode(m); !errors.Is(err, bech32.ErrInvalidVariant) {
+		t.Fatalf("expected %v, got %v", bech32.ErrInvalidVariant, err)
+	}
+
+	s, _ := bech32.EncodeBytes("umi", b[2:33], bech32.Bech32)
+	if _, err := bech32.Decode(s); !errors.Is(err, bech32.ErrInvalidLength) {
+		t.Fatalf("expected %v, got %v", bech32.ErrInvalidLength, err)
+	}
+}
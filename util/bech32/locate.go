@@ -0,0 +1,173 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bech32
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// MaxErrors is the number of substituted characters LocateErrors can find.
+// The checksum detects any 4 errors, so up to 2 can be corrected uniquely.
+const MaxErrors = 2
+
+var ErrTooManyErrors = errors.New("bech32: too many errors to locate")
+
+type location struct {
+	pos int
+	val int
+}
+
+// LocateErrors finds the characters of the data part of s that have to be
+// replaced for s to carry a valid checksum of variant v. It returns their
+// positions (0-based, in s) and the corrected string. A valid s yields no
+// positions. Errors in the human-readable part are not located.
+func LocateErrors(s string, v Variant) ([]int, string, error) {
+	if len(s) < 8 || len(s) > MaxLength {
+		return nil, "", ErrInvalidLength
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return nil, "", &InvalidCharError{Pos: i, Char: s[i]}
+		}
+	}
+
+	lower, upper := strings.ToLower(s), strings.ToUpper(s)
+	if s != lower && s != upper {
+		return nil, "", ErrMixedCase
+	}
+
+	one := strings.LastIndexByte(lower, '1')
+	if one < 1 || one+7 > len(lower) {
+		return nil, "", ErrInvalidSeparator
+	}
+
+	hrp := lower[:one]
+	data := make([]int, len(lower)-one-1)
+	erased := make(map[int]bool)
+
+	// Characters outside of the alphabet are certainly wrong, we decode
+	// them as zero and let the search find their real value.
+	for i := range data {
+		if idx := strings.IndexByte(Alphabet, lower[one+1+i]); idx >= 0 {
+			data[i] = idx
+		} else {
+			erased[i] = true
+		}
+	}
+
+	if len(erased) > MaxErrors {
+		return nil, "", ErrTooManyErrors
+	}
+
+	found, ok := search(polymod(append(hrpExpand(hrp), data...))^v.constant(), len(data))
+	if !ok {
+		return nil, "", ErrTooManyErrors
+	}
+
+	for _, l := range found {
+		data[l.pos] ^= l.val
+		erased[l.pos] = true
+	}
+
+	if len(erased) > MaxErrors {
+		return nil, "", ErrTooManyErrors
+	}
+
+	pos := make([]int, 0, len(erased))
+	for p := range erased {
+		pos = append(pos, one+1+p)
+	}
+
+	sort.Ints(pos)
+
+	b := []byte(lower)
+	for i, d := range data {
+		b[one+1+i] = Alphabet[d]
+	}
+
+	if s == upper {
+		return pos, strings.ToUpper(string(b)), nil
+	}
+
+	return pos, string(b), nil
+}
+
+// search finds at most MaxErrors substitutions in a data part of length n
+// whose effect on the checksum equals the syndrome. The checksum is affine
+// in the data, so the effect of a substitution does not depend on the
+// original value and the effects of several substitutions add up.
+func search(syndrome int, n int) ([]location, bool) {
+	if syndrome == 0 {
+		return nil, true
+	}
+
+	// effect[p][x] is the checksum difference caused by XOR-ing x into
+	// position p, it is computed from the last position backwards.
+	effect := make([][32]int, n)
+	for x := 1; x < 32; x++ {
+		effect[n-1][x] = x
+	}
+
+	for p := n - 2; p >= 0; p-- {
+		for x := 1; x < 32; x++ {
+			effect[p][x] = shift(effect[p+1][x])
+		}
+	}
+
+	single := make(map[int]location, n*31)
+
+	for p := 0; p < n; p++ {
+		for x := 1; x < 32; x++ {
+			if effect[p][x] == syndrome {
+				return []location{{p, x}}, true
+			}
+
+			single[effect[p][x]] = location{p, x}
+		}
+	}
+
+	for p := 0; p < n; p++ {
+		for x := 1; x < 32; x++ {
+			if l, ok := single[syndrome^effect[p][x]]; ok && l.pos > p {
+				return []location{{p, x}, l}, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// shift is a single polymod step with a zero input value.
+func shift(chk int) int {
+	b := chk >> 25
+	chk = (chk & 0x1ffffff) << 5
+
+	for i := 0; i < 5; i++ {
+		if (b>>uint(i))&1 == 1 {
+			chk ^= generator[i]
+		}
+	}
+
+	return chk
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bech32_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/umi-top/umi-core/util/bech32"
+)
+
+const adr = "umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhg"
+
+func TestLocateErrors(t *testing.T) {
+	cases := []struct {
+		desc string
+		str  string
+		pos  []int
+	}{
+		{"valid", adr, []int{}},
+		{"one", adr[:17] + "q" + adr[18:], []int{17}},
+		{"two", adr[:4] + "pp" + adr[6:], []int{4, 5}},
+		{"checksum", adr[:len(adr)-1] + "q", []int{61}},
+		{"not in alphabet", adr[:10] + "b" + adr[11:], []int{10}},
+		{"uppercase", "UMI1U3DAM33JAF64Z4S008G7SU62J4ZA72LJQFF9DTHSATAQ8K806NFSGRHDHQ", []int{61}},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			pos, fix, err := bech32.LocateErrors(tc.str, bech32.Bech32)
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if len(pos) != len(tc.pos) || (len(pos) > 0 && !reflect.DeepEqual(pos, tc.pos)) {
+				t.Fatalf("expected %v, got %v", tc.pos, pos)
+			}
+
+			if _, _, err := bech32.DecodeRef(fix); err != nil {
+				t.Fatalf("expected corrected string to be valid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLocateErrorsRandom(t *testing.T) {
+	const chars = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		b := []byte(adr)
+		n := 1 + rnd.Intn(2)
+
+		for j := 0; j < n; j++ {
+			p := 4 + rnd.Intn(len(adr)-4)
+			for b[p] == adr[p] {
+				b[p] = chars[rnd.Intn(32)]
+			}
+		}
+
+		_, fix, err := bech32.LocateErrors(string(b), bech32.Bech32)
+		if err != nil || fix != adr {
+			t.Fatalf("for %s expected %s, got %s (%v)", b, adr, fix, err)
+		}
+	}
+}
+
+func TestLocateErrorsTooMany(t *testing.T) {
+	cases := []string{
+		adr[:4] + "bio" + adr[7:],
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"[:8] + "llll" + "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"[12:],
+	}
+
+	for _, str := range cases {
+		if _, _, err := bech32.LocateErrors(str, bech32.Bech32); !errors.Is(err, bech32.ErrTooManyErrors) {
+			t.Errorf("for %s expected %v, got %v", str, bech32.ErrTooManyErrors, err)
+		}
+	}
+}
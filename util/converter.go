@@ -21,20 +21,48 @@
 package util
 
 import (
-	"strings"
+	"errors"
 )
 
-func PrefixToVersion(p string) uint16 {
-	if strings.Compare(p, "genesis") == 0 {
-		return 0
+const (
+	GenesisPrefix = "genesis"
+	UmiPrefix     = "umi"
+
+	// InvalidVersion is returned by PrefixToVersion for malformed prefixes,
+	// it can never be produced by a valid prefix.
+	InvalidVersion uint16 = 0xFFFF
+)
+
+var (
+	ErrInvalidPrefix  = errors.New("util: invalid prefix")
+	ErrInvalidVersion = errors.New("util: invalid version")
+)
+
+func ParsePrefix(p string) (uint16, error) {
+	if p == GenesisPrefix {
+		return 0, nil
 	}
 
-	return (uint16(p[0]-96) << 10) + (uint16(p[1]-96) << 5) + uint16(p[2]-96)
+	if len(p) != 3 {
+		return InvalidVersion, ErrInvalidPrefix
+	}
+
+	for i := 0; i < 3; i++ {
+		if p[i] < 'a' || p[i] > 'z' {
+			return InvalidVersion, ErrInvalidPrefix
+		}
+	}
+
+	return (uint16(p[0]-96) << 10) + (uint16(p[1]-96) << 5) + uint16(p[2]-96), nil
 }
 
-func VersionToPrefix(v uint16) string {
+func ParseVersion(v uint16) (string, error) {
 	if v == 0 {
-		return "genesis"
+		return GenesisPrefix, nil
+	}
+
+	if v&0x8000 != 0 {
+		return "", ErrInvalidVersion
 	}
 
 	p := make([]byte, 3)
@@ -42,5 +70,21 @@ func VersionToPrefix(v uint16) string {
 	p[1] = uint8(v&0x03E0>>5) + 96
 	p[2] = uint8(v&0x001F) + 96
 
-	return string(p)
+	for i := 0; i < 3; i++ {
+		if p[i] < 'a' || p[i] > 'z' {
+			return "", ErrInvalidVersion
+		}
+	}
+
+	return string(p), nil
+}
+
+func PrefixToVersion(p string) uint16 {
+	v, _ := ParsePrefix(p)
+	return v
+}
+
+func VersionToPrefix(v uint16) string {
+	p, _ := ParseVersion(v)
+	return p
 }
@@ -21,6 +21,7 @@
 package util_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -56,3 +57,46 @@ func TestVersionToPrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePrefixInvalid(t *testing.T) {
+	for _, p := range []string{"", "a", "ab", "abcd", "ABC", "a1c", "um{", "Genesis", "umi "} {
+		ver, err := util.ParsePrefix(p)
+		if !errors.Is(err, util.ErrInvalidPrefix) {
+			t.Error("For", p, "expected", util.ErrInvalidPrefix, "got", err)
+		}
+
+		if ver != util.InvalidVersion {
+			t.Error("For", p, "expected", util.InvalidVersion, "got", ver)
+		}
+
+		if ver := util.PrefixToVersion(p); ver != util.InvalidVersion {
+			t.Error("For", p, "expected", util.InvalidVersion, "got", ver)
+		}
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, v := range []uint16{util.InvalidVersion, 0x8000 | 21929, 1, 32, 1024, 27 << 10} {
+		_, err := util.ParseVersion(v)
+		if !errors.Is(err, util.ErrInvalidVersion) {
+			t.Error("For", v, "expected", util.ErrInvalidVersion, "got", err)
+		}
+
+		if hrp := util.VersionToPrefix(v); hrp != "" {
+			t.Error("For", v, "expected empty prefix, got", hrp)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for v := uint16(0); v < 0x8000; v++ {
+		p, err := util.ParseVersion(v)
+		if err != nil {
+			continue
+		}
+
+		if w, err := util.ParsePrefix(p); err != nil || w != v {
+			t.Fatal("For", v, "expected", v, "got", w, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package util
+
+import (
+	"sort"
+	"sync"
+)
+
+var systemPrefixes = map[string]struct{}{
+	GenesisPrefix: {},
+	UmiPrefix:     {},
+}
+
+var (
+	knownMu       sync.RWMutex
+	knownPrefixes = map[string]struct{}{
+		GenesisPrefix: {},
+		UmiPrefix:     {},
+	}
+)
+
+// IsSystemPrefix reports whether p is reserved by the network itself
+// and therefore can not be used by a structure.
+func IsSystemPrefix(p string) bool {
+	_, ok := systemPrefixes[p]
+	return ok
+}
+
+// RegisterPrefix adds a structure prefix to the set of known prefixes.
+func RegisterPrefix(p string) error {
+	if _, err := ParsePrefix(p); err != nil {
+		return err
+	}
+
+	knownMu.Lock()
+	knownPrefixes[p] = struct{}{}
+	knownMu.Unlock()
+
+	return nil
+}
+
+// UnregisterPrefix removes a structure prefix added by RegisterPrefix,
+// system prefixes stay known.
+func UnregisterPrefix(p string) {
+	if IsSystemPrefix(p) {
+		return
+	}
+
+	knownMu.Lock()
+	delete(knownPrefixes, p)
+	knownMu.Unlock()
+}
+
+func IsKnownPrefix(p string) bool {
+	knownMu.RLock()
+	_, ok := knownPrefixes[p]
+	knownMu.RUnlock()
+
+	return ok
+}
+
+func KnownPrefixes() []string {
+	knownMu.RLock()
+	p := make([]string, 0, len(knownPrefixes))
+
+	for k := range knownPrefixes {
+		p = append(p, k)
+	}
+	knownMu.RUnlock()
+
+	sort.Strings(p)
+
+	return p
+}
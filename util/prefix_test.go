@@ -0,0 +1,78 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/umi-top/umi-core/util"
+)
+
+func TestIsSystemPrefix(t *testing.T) {
+	cases := map[string]bool{"umi": true, "genesis": true, "aaa": false, "": false}
+
+	for p, exp := range cases {
+		if act := util.IsSystemPrefix(p); act != exp {
+			t.Error("For", p, "expected", exp, "got", act)
+		}
+	}
+}
+
+func TestRegisterPrefix(t *testing.T) {
+	if util.IsKnownPrefix("sfn") {
+		t.Fatal("Expected sfn to be unknown")
+	}
+
+	if err := util.RegisterPrefix("sfn"); err != nil {
+		t.Fatal("Expected nil, got", err)
+	}
+
+	t.Cleanup(func() { util.UnregisterPrefix("sfn") })
+
+	if !util.IsKnownPrefix("sfn") || util.IsSystemPrefix("sfn") {
+		t.Fatal("Expected sfn to be known and not system")
+	}
+
+	if err := util.RegisterPrefix("SFN"); !errors.Is(err, util.ErrInvalidPrefix) {
+		t.Fatal("Expected", util.ErrInvalidPrefix, "got", err)
+	}
+
+	act := util.KnownPrefixes()
+	exp := []string{"genesis", "sfn", "umi"}
+
+	if len(act) != len(exp) {
+		t.Fatal("Expected", exp, "got", act)
+	}
+
+	for i := range exp {
+		if exp[i] != act[i] {
+			t.Fatal("Expected", exp, "got", act)
+		}
+	}
+
+	util.UnregisterPrefix("sfn")
+	util.UnregisterPrefix("umi")
+
+	if util.IsKnownPrefix("sfn") || !util.IsKnownPrefix("umi") {
+		t.Fatal("Expected sfn to be unknown and umi to stay known")
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package vanity searches for keys whose bech32 address matches a pattern.
+package vanity
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/key"
+	"github.com/umi-top/umi-core/util"
+	"github.com/umi-top/umi-core/util/bech32"
+)
+
+const (
+	// keyBits is the length of the bech32 payload in bits, the public key
+	// without the two-byte version.
+	keyBits = (address.Length - 2) * 8
+	// lastKeyChar is the index of the character that carries the last bit
+	// of the public key and four zero padding bits, it is either q or s.
+	// The characters before it hold five key bits each and can take any
+	// value, so Start is limited to lastKeyChar characters.
+	lastKeyChar = keyBits / 5
+	// dataLength is the number of characters after the separator: 52 for
+	// the public key and 6 for the checksum.
+	dataLength = (keyBits+4)/5 + 6
+)
+
+var ErrInvalidPattern = errors.New("vanity: invalid pattern")
+
+// Pattern constrains the part of the address after "prefix1". Matching is
+// case-insensitive. Start may be at most 51 characters long, the ones that
+// hold five bits of the public key each.
+type Pattern struct {
+	Prefix   string
+	Start    string
+	Contains string
+	End      string
+}
+
+func (p Pattern) normalize() Pattern {
+	if p.Prefix == "" {
+		p.Prefix = util.UmiPrefix
+	}
+
+	p.Start = strings.ToLower(p.Start)
+	p.Contains = strings.ToLower(p.Contains)
+	p.End = strings.ToLower(p.End)
+
+	return p
+}
+
+// Validate reports whether addresses matching p can exist.
+func (p Pattern) Validate() error {
+	p = p.normalize()
+
+	if _, err := util.ParsePrefix(p.Prefix); err != nil || p.Prefix == util.GenesisPrefix {
+		return ErrInvalidPattern
+	}
+
+	for _, s := range []string{p.Start, p.Contains, p.End} {
+		for i := 0; i < len(s); i++ {
+			if strings.IndexByte(bech32.Alphabet, s[i]) < 0 {
+				return ErrInvalidPattern
+			}
+		}
+	}
+
+	if len(p.Start) > lastKeyChar || len(p.Contains) > dataLength || len(p.Start)+len(p.End) > dataLength {
+		return ErrInvalidPattern
+	}
+
+	var fixed [dataLength]byte
+
+	copy(fixed[:], p.Start)
+	copy(fixed[dataLength-len(p.End):], p.End)
+
+	if !possible(lastKeyChar, fixed[lastKeyChar]) {
+		return ErrInvalidPattern
+	}
+
+	if p.Contains == "" {
+		return nil
+	}
+
+	for off := 0; off+len(p.Contains) <= dataLength; off++ {
+		ok := true
+
+		for i := 0; i < len(p.Contains) && ok; i++ {
+			c := p.Contains[i]
+			ok = possible(off+i, c) && (fixed[off+i] == 0 || fixed[off+i] == c)
+		}
+
+		if ok {
+			return nil
+		}
+	}
+
+	return ErrInvalidPattern
+}
+
+// chance is the probability that the character at index i of the data
+// part is c, a zero c matches anything.
+func chance(i int, c byte) float64 {
+	switch {
+	case c == 0:
+		return 1
+	case i != lastKeyChar:
+		return 1.0 / 32
+	case c == 'q' || c == 's':
+		return 1.0 / 2
+	default:
+		return 0
+	}
+}
+
+func possible(i int, c byte) bool {
+	return chance(i, c) > 0
+}
+
+// Match reports whether the bech32 address s matches p.
+func (p Pattern) Match(s string) bool {
+	p = p.normalize()
+
+	data := strings.TrimPrefix(strings.ToLower(s), p.Prefix+"1")
+	if len(data) != dataLength {
+		return false
+	}
+
+	return strings.HasPrefix(data, p.Start) && strings.HasSuffix(data, p.End) && strings.Contains(data, p.Contains)
+}
+
+// Difficulty estimates the expected number of attempts, assuming every
+// character is uniform over the 32-letter alphabet except the one holding
+// the last key bit, which is q or s. Contains is counted once per possible
+// position. Patterns that can not match have an infinite difficulty.
+func (p Pattern) Difficulty() float64 {
+	p = p.normalize()
+
+	if len(p.Start)+len(p.End) > dataLength || len(p.Contains) > dataLength {
+		return math.Inf(1)
+	}
+
+	prob := 1.0
+
+	for i := 0; i < len(p.Start); i++ {
+		prob *= chance(i, p.Start[i])
+	}
+
+	for i, off := 0, dataLength-len(p.End); i < len(p.End); i++ {
+		prob *= chance(off+i, p.End[i])
+	}
+
+	if n := len(p.Contains); n > 0 {
+		var sum float64
+
+		for off := 0; off+n <= dataLength; off++ {
+			q := 1.0
+			for i := 0; i < n; i++ {
+				q *= chance(off+i, p.Contains[i])
+			}
+
+			sum += q
+		}
+
+		prob *= sum
+	}
+
+	if prob == 0 {
+		return math.Inf(1)
+	}
+
+	return math.Max(1/prob, 1)
+}
+
+// Progress is reported periodically during a search.
+type Progress struct {
+	Attempts uint64
+	Elapsed  time.Duration
+	Rate     float64
+	// Probability that a match would have been found by now.
+	Probability float64
+}
+
+type Options struct {
+	// Workers defaults to the number of CPUs.
+	Workers int
+	// Progress, if set, is called every Interval, one second by default.
+	Progress func(Progress)
+	Interval time.Duration
+	// Rand defaults to crypto/rand.
+	Rand io.Reader
+}
+
+// Search generates keys until the address of one matches p or ctx is done.
+func Search(ctx context.Context, p Pattern, opt Options) (*key.SecretKey, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	p = p.normalize()
+
+	if opt.Workers <= 0 {
+		opt.Workers = runtime.NumCPU()
+	}
+
+	if opt.Interval <= 0 {
+		opt.Interval = time.Second
+	}
+
+	if opt.Rand == nil {
+		opt.Rand = rand.Reader
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		attempts uint64
+		once     sync.Once
+		found    *key.SecretKey
+		werr     error
+		wg       sync.WaitGroup
+		randMu   sync.Mutex
+	)
+
+	for i := 0; i < opt.Workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			seed := make([]byte, key.SeedLength)
+
+			for ctx.Err() == nil {
+				randMu.Lock()
+				_, err := io.ReadFull(opt.Rand, seed)
+				randMu.Unlock()
+
+				if err != nil {
+					once.Do(func() { werr = err })
+					cancel()
+
+					return
+				}
+
+				k := key.NewSecretKeyFromSeed(seed)
+				atomic.AddUint64(&attempts, 1)
+
+				if p.Match(address.FromKey(k).SetPrefix(p.Prefix).ToBech32()) {
+					once.Do(func() { found = k })
+					cancel()
+
+					return
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	if opt.Progress != nil {
+		start, diff := time.Now(), p.Difficulty()
+		t := time.NewTicker(opt.Interval)
+
+	loop:
+		for {
+			select {
+			case <-t.C:
+				n, el := atomic.LoadUint64(&attempts), time.Since(start)
+				opt.Progress(Progress{
+					Attempts:    n,
+					Elapsed:     el,
+					Rate:        float64(n) / el.Seconds(),
+					Probability: 1 - math.Exp(-float64(n)/diff),
+				})
+			case <-done:
+				break loop
+			}
+		}
+
+		t.Stop()
+	}
+
+	<-done
+
+	if found != nil {
+		return found, nil
+	}
+
+	if werr != nil {
+		return nil, werr
+	}
+
+	return nil, ctx.Err()
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package vanity_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/umi-top/umi-core/address"
+	"github.com/umi-top/umi-core/vanity"
+)
+
+func TestPattern(t *testing.T) {
+	const adr = "umi1u3dam33jaf64z4s008g7su62j4za72ljqff9dthsataq8k806nfsgrhdhg"
+
+	cases := []struct {
+		p   vanity.Pattern
+		exp bool
+	}{
+		{vanity.Pattern{}, true},
+		{vanity.Pattern{Start: "u3dam"}, true},
+		{vanity.Pattern{Start: "U3DAM"}, true},
+		{vanity.Pattern{Contains: "z4s00"}, true},
+		{vanity.Pattern{End: "rhdhg"}, true},
+		{vanity.Pattern{Start: "u3", Contains: "ataq", End: "hg"}, true},
+		{vanity.Pattern{Start: "3dam"}, false},
+		{vanity.Pattern{Prefix: "aaa", Start: "u3"}, false},
+	}
+
+	for _, tc := range cases {
+		if act := tc.p.Match(adr); act != tc.exp {
+			t.Fatalf("%+v: Expected: %v, got: %v", tc.p, tc.exp, act)
+		}
+	}
+
+	for _, p := range []vanity.Pattern{{Start: "b"}, {Contains: "1"}, {End: "io"}, {Prefix: "genesis"},
+		{Start: strings.Repeat("q", 52)}, {End: "zzzzzzz"}, {End: strings.Repeat("q", 6) + "z" + strings.Repeat("q", 6)},
+		{Contains: strings.Repeat("z", 58)}, {Start: strings.Repeat("q", 46), Contains: "zzzzzz", End: strings.Repeat("q", 7)}} {
+		if err := p.Validate(); !errors.Is(err, vanity.ErrInvalidPattern) {
+			t.Fatalf("%+v: Expected: %v, got: %v", p, vanity.ErrInvalidPattern, err)
+		}
+	}
+}
+
+func TestDifficulty(t *testing.T) {
+	cases := []struct {
+		p   vanity.Pattern
+		exp float64
+	}{
+		{vanity.Pattern{}, 1},
+		{vanity.Pattern{Start: "ab"}, 1024},
+		{vanity.Pattern{Start: "a", End: "b"}, 1024},
+		{vanity.Pattern{Contains: "ab"}, 1024.0 / 55},
+		{vanity.Pattern{Contains: "a"}, 1},
+		{vanity.Pattern{End: "qqqqqqq"}, 2 << 30},
+		{vanity.Pattern{End: "zzzzzzz"}, math.Inf(1)},
+	}
+
+	for _, tc := range cases {
+		if act := tc.p.Difficulty(); act != tc.exp {
+			t.Fatalf("%+v: Expected: %v, got: %v", tc.p, tc.exp, act)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	p := vanity.Pattern{Prefix: "aaa", Start: "u"}
+
+	k, err := vanity.Search(context.Background(), p, vanity.Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("Expected: nil, got: %v", err)
+	}
+
+	if adr := address.FromKey(k).SetPrefix("aaa").ToBech32(); !strings.HasPrefix(adr, "aaa1u") {
+		t.Fatalf("Expected: aaa1u..., got: %s", adr)
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := vanity.Search(ctx, vanity.Pattern{}, vanity.Options{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected: %v, got: %v", context.Canceled, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	var last vanity.Progress
+
+	_, err := vanity.Search(ctx, vanity.Pattern{Start: "qqqqqqqqqq"}, vanity.Options{
+		Interval: time.Millisecond,
+		Progress: func(p vanity.Progress) {
+			if last = p; p.Attempts > 0 {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected: %v, got: %v", context.Canceled, err)
+	}
+
+	if last.Attempts == 0 || last.Probability >= 0.01 {
+		t.Fatalf("Expected: progress to be reported, got: %+v", last)
+	}
+}